@@ -0,0 +1,287 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Package admin serves JSON endpoints with internals of the running context: beans, wiring graph, properties, health and startup timings,
+and allows to reload the bean or refresh properties.
+
+Example:
+	http.Handle("/admin/", http.StripPrefix("/admin", admin.Handler(ctx)))
+*/
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.arpabet.com/beans"
+	"net/http"
+	"sort"
+	"time"
+)
+
+/**
+Information about the bean
+*/
+type BeanInfo struct {
+	/**
+	Name of the bean
+	*/
+	Name string `json:"name"`
+	/**
+	Type of the bean
+	*/
+	Type string `json:"type"`
+	/**
+	Lifecycle state of the bean
+	*/
+	Lifecycle string `json:"lifecycle"`
+	/**
+	Level of the context where bean is defined, 0 is the context of the handler, 1 is the parent and so on
+	*/
+	Level int `json:"level"`
+	/**
+	Type of factory bean that created the bean
+	*/
+	Factory string `json:"factory,omitempty"`
+	/**
+	Names of injected beans
+	*/
+	Dependencies []string `json:"dependencies,omitempty"`
+	/**
+	Duration of PostConstruct or factory Object call
+	*/
+	InitDuration string `json:"initDuration"`
+}
+
+/**
+Dependency of the bean in the wiring graph
+*/
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+/**
+Wiring graph of the context
+*/
+type Graph struct {
+	Nodes []string `json:"nodes"`
+	Edges []Edge   `json:"edges"`
+}
+
+/**
+Initialization time of the bean
+*/
+type Timing struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Duration time.Duration `json:"duration"`
+}
+
+/**
+Creates the handler with endpoints:
+	GET  /beans       beans of this and parent contexts with lifecycle state
+	GET  /graph       wiring graph
+	GET  /properties  properties with redacted secrets
+	GET  /health      health report, 'probe' parameter is 'readiness' (default), 'liveness' or 'all', status 503 if down
+	GET  /timings     beans ordered by initialization duration
+	POST /reload      reloads the bean by 'bean' parameter, 'cascade=true' reloads dependent beans too
+	POST /refresh     refreshes properties
+*/
+func Handler(ctx beans.Context) http.Handler {
+	h := &handler{ctx: ctx}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/beans", h.get(h.beans))
+	mux.HandleFunc("/graph", h.get(h.graph))
+	mux.HandleFunc("/properties", h.get(h.properties))
+	mux.HandleFunc("/health", h.get(h.health))
+	mux.HandleFunc("/timings", h.get(h.timings))
+	mux.HandleFunc("/reload", h.post(h.reload))
+	mux.HandleFunc("/refresh", h.post(h.refresh))
+	return mux
+}
+
+type handler struct {
+	ctx beans.Context
+}
+
+type levelBean struct {
+	bean  beans.Bean
+	level int
+	ctx   beans.Context
+}
+
+func (t *handler) get(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (t *handler) post(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+			return
+		}
+		fn(w, r)
+	}
+}
+
+/**
+Returns beans of this and parent contexts sorted by level and name
+*/
+func (t *handler) allBeans() []levelBean {
+	var list []levelBean
+	visited := make(map[beans.Bean]bool)
+	level := 0
+	for ctx, ok := t.ctx, true; ok; ctx, ok = ctx.Parent() {
+		for _, typ := range ctx.Core() {
+			for _, b := range ctx.Bean(typ, 1) {
+				if !visited[b] {
+					visited[b] = true
+					list = append(list, levelBean{bean: b, level: level, ctx: ctx})
+				}
+			}
+		}
+		level++
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].level != list[j].level {
+			return list[i].level < list[j].level
+		}
+		return list[i].bean.Name() < list[j].bean.Name()
+	})
+	return list
+}
+
+func (t *handler) beans(w http.ResponseWriter, r *http.Request) {
+	list := make([]BeanInfo, 0)
+	for _, lb := range t.allBeans() {
+		b := lb.bean
+		info := BeanInfo{
+			Name:         b.Name(),
+			Type:         b.Class().String(),
+			Lifecycle:    b.Lifecycle().String(),
+			Level:        lb.level,
+			InitDuration: b.InitDuration().String(),
+		}
+		if factory, ok := b.FactoryBean(); ok {
+			info.Factory = factory.Class().String()
+		}
+		for _, dep := range b.Dependencies() {
+			info.Dependencies = append(info.Dependencies, dep.Name())
+		}
+		list = append(list, info)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (t *handler) graph(w http.ResponseWriter, r *http.Request) {
+	graph := Graph{Nodes: make([]string, 0), Edges: make([]Edge, 0)}
+	for _, lb := range t.allBeans() {
+		graph.Nodes = append(graph.Nodes, lb.bean.Name())
+		for _, dep := range lb.bean.Dependencies() {
+			graph.Edges = append(graph.Edges, Edge{From: lb.bean.Name(), To: dep.Name()})
+		}
+	}
+	writeJSON(w, http.StatusOK, graph)
+}
+
+func (t *handler) properties(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, t.ctx.Properties())
+}
+
+func (t *handler) health(w http.ResponseWriter, r *http.Request) {
+	var probe beans.HealthProbe
+	switch r.URL.Query().Get("probe") {
+	case "", "readiness":
+		probe = beans.ReadinessProbe
+	case "liveness":
+		probe = beans.LivenessProbe
+	case "all":
+		probe = beans.LivenessProbe | beans.ReadinessProbe
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown probe '%s'", r.URL.Query().Get("probe")))
+		return
+	}
+	report := t.ctx.CheckHealth(r.Context(), probe)
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func (t *handler) timings(w http.ResponseWriter, r *http.Request) {
+	list := make([]Timing, 0)
+	for _, lb := range t.allBeans() {
+		list = append(list, Timing{Name: lb.bean.Name(), Type: lb.bean.Class().String(), Duration: lb.bean.InitDuration()})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Duration > list[j].Duration
+	})
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (t *handler) reload(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("bean")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("parameter 'bean' is required"))
+		return
+	}
+	// the nearest context having the bean, like Lookup with DefaultLevel, but among all beans of contexts
+	var list []levelBean
+	for _, lb := range t.allBeans() {
+		if lb.bean.Name() == name && (len(list) == 0 || list[0].level == lb.level) {
+			list = append(list, lb)
+		}
+	}
+	switch len(list) {
+	case 0:
+		writeError(w, http.StatusNotFound, fmt.Errorf("bean '%s' not found", name))
+		return
+	case 1:
+	default:
+		writeError(w, http.StatusConflict, fmt.Errorf("bean '%s' is ambiguous, found %d beans", name, len(list)))
+		return
+	}
+	lb := list[0]
+	var err error
+	if r.URL.Query().Get("cascade") == "true" {
+		err = lb.ctx.ReloadCascade(lb.bean)
+	} else {
+		err = lb.ctx.Reload(lb.bean)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"bean": lb.bean.Name(), "lifecycle": lb.bean.Lifecycle().String()})
+}
+
+func (t *handler) refresh(w http.ResponseWriter, r *http.Request) {
+	if err := t.ctx.Refresh(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "refreshed"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,149 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"go.arpabet.com/beans/admin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type secretSource struct {
+}
+
+func (t *secretSource) Properties() (map[string]string, error) {
+	return map[string]string{"db.user": "admin", "db.password": "qwerty"}, nil
+}
+
+func (t *secretSource) IsSecret(key string) bool {
+	return key == "db.password"
+}
+
+type storage struct {
+	User     string `value:"db.user"`
+	down     bool
+	reloaded int
+}
+
+func (t *storage) PostConstruct() error {
+	time.Sleep(time.Millisecond)
+	t.reloaded++
+	return nil
+}
+
+func (t *storage) Health(ctx context.Context) beans.HealthStatus {
+	if t.down {
+		return beans.HealthStatus{State: beans.HealthDown}
+	}
+	return beans.HealthStatus{State: beans.HealthUp}
+}
+
+type auditLog struct {
+	reloaded int
+}
+
+func (t *auditLog) PostConstruct() error {
+	t.reloaded++
+	return nil
+}
+
+type userService struct {
+	Storage *storage `inject`
+}
+
+func request(t *testing.T, h http.Handler, method, url string, value interface{}) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	if value != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), value))
+	}
+	return w.Code
+}
+
+func TestAdmin(t *testing.T) {
+
+	db := &storage{}
+	parent, err := beans.Create(&secretSource{}, db)
+	require.NoError(t, err)
+	defer parent.Close()
+
+	ctx, err := parent.Extend(&userService{})
+	require.NoError(t, err)
+
+	h := admin.Handler(ctx)
+
+	var list []admin.BeanInfo
+	require.Equal(t, http.StatusOK, request(t, h, "GET", "/beans", &list))
+	found := make(map[string]admin.BeanInfo)
+	for _, info := range list {
+		found[info.Name] = info
+	}
+	require.Equal(t, 0, found["*admin_test.userService"].Level)
+	require.Equal(t, []string{"*admin_test.storage"}, found["*admin_test.userService"].Dependencies)
+	require.Equal(t, 1, found["*admin_test.storage"].Level)
+	require.Equal(t, "BeanInitialized", found["*admin_test.storage"].Lifecycle)
+
+	var graph admin.Graph
+	require.Equal(t, http.StatusOK, request(t, h, "GET", "/graph", &graph))
+	require.Contains(t, graph.Edges, admin.Edge{From: "*admin_test.userService", To: "*admin_test.storage"})
+
+	var props map[string]string
+	require.Equal(t, http.StatusOK, request(t, h, "GET", "/properties", &props))
+	require.Equal(t, "admin", props["db.user"])
+	require.NotEqual(t, "qwerty", props["db.password"])
+
+	var timings []admin.Timing
+	require.Equal(t, http.StatusOK, request(t, h, "GET", "/timings", &timings))
+	require.Equal(t, "*admin_test.storage", timings[0].Name)
+	require.True(t, timings[0].Duration >= time.Millisecond)
+
+	require.Equal(t, http.StatusOK, request(t, h, "GET", "/health", nil))
+	db.down = true
+	var report beans.HealthReport
+	require.Equal(t, http.StatusServiceUnavailable, request(t, h, "GET", "/health?probe=readiness", &report))
+	require.Equal(t, beans.HealthDown, report.State)
+	require.Equal(t, http.StatusOK, request(t, h, "GET", "/health?probe=liveness", nil))
+	require.Equal(t, http.StatusBadRequest, request(t, h, "GET", "/health?probe=unknown", nil))
+
+	require.Equal(t, http.StatusMethodNotAllowed, request(t, h, "GET", "/reload?bean=*admin_test.storage", nil))
+	require.Equal(t, http.StatusNotFound, request(t, h, "POST", "/reload?bean=unknown", nil))
+	require.Equal(t, http.StatusOK, request(t, h, "POST", "/reload?bean=*admin_test.storage", nil))
+	require.Equal(t, 2, db.reloaded)
+
+	require.Equal(t, uint64(1), parent.Metrics().Reloads)
+
+	require.Equal(t, http.StatusMethodNotAllowed, request(t, h, "DELETE", "/refresh", nil))
+	require.Equal(t, http.StatusOK, request(t, h, "POST", "/refresh", nil))
+}
+
+func TestAdminReloadNotLookedUp(t *testing.T) {
+
+	audit := &auditLog{}
+	parent, err := beans.Create(audit)
+	require.NoError(t, err)
+	defer parent.Close()
+
+	ctx, err := parent.Extend(beans.PropertyMap(map[string]string{"db.user": "admin"}), &userService{}, &storage{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	h := admin.Handler(ctx)
+
+	// the bean was never injected or looked up, so it is not in the registry
+	require.Empty(t, ctx.Lookup("*admin_test.auditLog", beans.DefaultLevel))
+	require.Equal(t, http.StatusOK, request(t, h, "POST", "/reload?bean=*admin_test.auditLog", nil))
+	require.Equal(t, 2, audit.reloaded)
+	require.Equal(t, uint64(1), parent.Metrics().Reloads)
+
+	require.Equal(t, http.StatusOK, request(t, h, "POST", "/reload?bean=*admin_test.userService&cascade=true", nil))
+	require.Equal(t, uint64(1), ctx.Metrics().Reloads)
+}
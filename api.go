@@ -4,7 +4,12 @@
 
 package beans
 
-import "reflect"
+import (
+	gocontext "context"
+	"github.com/pkg/errors"
+	"reflect"
+	"time"
+)
 
 type BeanLifecycle int32
 
@@ -69,7 +74,9 @@ type Bean interface {
 	Re-initialize bean by calling Destroy method if bean implements DisposableBean interface
 	and then calls PostConstruct method if bean implements InitializingBean interface
 
-	Reload can not be used for beans created by FactoryBean, since the instances are already injected
+	Reload can not be used for beans created by FactoryBean, since the instances are already injected.
+	Dependent beans are not re-initialized, use Context.ReloadCascade or Context.ReloadFactory for that.
+	Bean of the context is reloaded by Context.Reload, so it must not be called from callbacks of the running context change.
 	*/
 	Reload() error
 
@@ -78,6 +85,16 @@ type Bean interface {
 	*/
 	Lifecycle() BeanLifecycle
 
+	/**
+	Returns beans injected into this bean, including factory beans of injected objects
+	*/
+	Dependencies() []Bean
+
+	/**
+	Returns the duration of the last PostConstruct call or factory Object call that created the bean, dependencies are not included
+	*/
+	InitDuration() time.Duration
+
 	/**
 	Returns information about the bean
 	*/
@@ -87,6 +104,11 @@ type Bean interface {
 var ContextClass = reflect.TypeOf((*Context)(nil)).Elem()
 
 type Context interface {
+
+	/**
+	Context publishes events to listeners of this and parent contexts
+	*/
+	EventPublisher
 	/**
 	Gets parent context if exist
 	*/
@@ -145,7 +167,9 @@ type Context interface {
 	/**
 	Inject fields in to the obj on runtime that is not part of core context.
 	Does not add a new bean in to the core context, so this method is only for one-time use with scope 'runtime'.
-	Does not initialize bean and does not destroy it.
+	Does not initialize bean and does not destroy it, use Build for that.
+	All options of 'inject' tag are supported: optional fields are skipped if candidates not found,
+	lazy fields accept non-initialized beans, non-singleton factories produce new objects for each call.
 
 	Example:
 		type requestProcessor struct {
@@ -159,12 +183,169 @@ type Context interface {
 
 	Inject(interface{}) error
 
+	/**
+	Inject fields in to the obj on runtime and call PostConstruct method if obj implements InitializingBean interface.
+	Returns handle that calls Destroy method if obj implements DisposableBean interface on Close.
+	Does not add a new bean in to the core context, so the handle must be closed by the caller.
+
+	Example:
+		h, err := ctx.Build(new(requestProcessor))
+		if err != nil {
+			return err
+		}
+		defer h.Close()
+		rp := h.Object().(*requestProcessor)
+	*/
+	Build(obj interface{}) (Handle, error)
+
+	/**
+	Register new beans in the running context.
+	Scans, injects and initializes new beans against existing beans in this and parent contexts.
+	New beans become visible for Bean and Lookup calls and added to slice and map fields with 'dynamic' option of existing beans.
+	Existing fields without 'dynamic' option are not updated.
+	If initialization or start of any new bean fails, started beans are stopped, constructed beans are destroyed and none of them is registered.
+
+	Example:
+		type pluginHost struct {
+			Plugins []Plugin `inject:"optional,dynamic"`
+		}
+
+		err := ctx.Register(&myPlugin{})
+	*/
+	Register(scan ...interface{}) error
+
+	/**
+	Unregister the bean from the running context.
+	Refuses if non-lazy fields of other beans in this or child contexts hold the bean, except slice and map fields with 'dynamic' option.
+	Otherwise calls Destroy method if bean implements DisposableBean interface and removes it from the context and collections.
+	Unregister of FactoryBean removes produced beans as well.
+	*/
+	Unregister(bean Bean) error
+
+	/**
+	Reload the bean of this context by Destroy and PostConstruct calls, dependents are not re-initialized.
+	The reload is serialized with other changes of the context, counted in metrics, traced and publishes BeanReloadedEvent.
+	Beans produced by FactoryBean can be reloaded only by ReloadFactory.
+	*/
+	Reload(bean Bean) error
+
+	/**
+	Reload the bean together with all beans in this and child contexts that depend on it directly or transitively through non-lazy fields.
+	Dependents are destroyed in reverse initialization order, then the bean is reloaded by Destroy and PostConstruct calls,
+	then dependents are initialized again by PostConstruct calls in initialization order.
+	Beans produced by FactoryBean can be reloaded only by ReloadFactory.
+	*/
+	ReloadCascade(bean Bean) error
+
+	/**
+	Reload the singleton FactoryBean and re-inject the new produced object in to all fields that hold the previous one,
+	then re-initialize dependents of produced object like ReloadCascade does.
+	The bean could be the factory bean itself or the bean produced by it.
+	The previous object is not destroyed, since context never destroys objects produced by factories.
+	*/
+	ReloadFactory(bean Bean) error
+
+	/**
+	Replace the object of the bean by the new instance of the same pointer type.
+	The new instance is injected and initialized by PostConstruct call, then all fields in this and child contexts
+	that hold the previous object are re-injected, pointer and map fields are updated by atomic stores.
+	Slices and maps are not modified in place, fields get updated copies, but slice and interface fields are updated by plain stores.
+	The previous object is stopped and destroyed after all references were switched, Replace does not wait for callers that still use it.
+	Replace gives no guarantees for consumers reading injected fields concurrently, plain reads of replaced fields are data races,
+	so consumers must synchronize access themselves, for example call Replace while the consumer does not serve requests.
+	If the new instance fails to initialize or start, the bean keeps the previous object and the initialized new instance is destroyed.
+	Objects injected on runtime by Inject or Build are not tracked and keep the previous object.
+	FactoryBean and beans produced by it can not be replaced, use ReloadFactory for them.
+	*/
+	Replace(bean Bean, obj interface{}) error
+
+	/**
+	Returns the value of the property from property sources of this or parent contexts
+	*/
+	Property(key string) (string, bool)
+
+	/**
+	Returns all properties of this and parent contexts, values of secret properties are redacted
+	*/
+	Properties() map[string]string
+
+	/**
+	Reads property sources of this and child contexts again.
+	For each context with changed properties binds 'value' fields of RefreshableBean beans again in initialization order,
+	calls OnRefresh on them and notifies listeners of changed properties.
+	OnRefresh, listeners and ContextRefreshedEvent are called after all beans are bound, so they could reload beans or change the context.
+	Nothing is changed if any property source fails.
+	*/
+	Refresh() error
+
+	/**
+	Adds the listener of the property change on Refresh, returns function that removes the listener
+	*/
+	OnPropertyChange(key string, listener PropertyListener) (cancel func())
+
+	/**
+	Starts Lifecycle beans of this and child contexts that are not running, in phase order.
+	Context starts beans automatically on creation, this method resumes them after Stop.
+	On failure stops already started beans of the failed context.
+	*/
+	Start() error
+
+	/**
+	Stops running Lifecycle beans of child contexts and this context in reverse phase order, without destroying them.
+	Close stops beans automatically before destroy.
+	*/
+	Stop() error
+
+	/**
+	Blocks until all services of the context exit, returns errors of services that failed after all restarts
+	*/
+	Wait() error
+
+	/**
+	Returns the channel that is closed when the context starts closing, either by Close or by escalation of the failed service
+	*/
+	Done() <-chan struct{}
+
+	/**
+	Returns the error of the service that closed the context by escalation, or nil
+	*/
+	Err() error
+
+	/**
+	Runs checks of HealthIndicator beans of this and parent contexts for the probe concurrently, each check is bounded by DefaultHealthTimeout.
+	The report is down if the context is closing, if any check is down or did not finish in time.
+	*/
+	CheckHealth(ctx gocontext.Context, probe HealthProbe) *HealthReport
+
+	/**
+	Returns the snapshot of metrics of this context: number of beans, durations of PostConstruct, factory Object and Destroy calls,
+	failures by phase, reloads, runtime Inject calls and registry lookups
+	*/
+	Metrics() Metrics
+
 	/**
 	Returns information about context
 	*/
 	String() string
 }
 
+/**
+Handle of the runtime object created by Context.Build
+*/
+
+type Handle interface {
+
+	/**
+	Returns injected and initialized runtime object
+	*/
+	Object() interface{}
+
+	/**
+	Calls Destroy method if object implements DisposableBean interface, only once
+	*/
+	Close() error
+}
+
 /**
 This interface used to provide pre-scanned instances in beans.Create method
 */
@@ -211,6 +392,76 @@ type FactoryBean interface {
 	Singleton() bool
 }
 
+/**
+Injection point describes the field that is going to receive the object produced by the factory.
+*/
+
+type InjectionPoint struct {
+
+	/**
+	Type of the consumer, that is a pointer to the structure where injection is going to be happen
+	*/
+	Class reflect.Type
+
+	/**
+	Name of the field in the consumer structure
+	*/
+	FieldName string
+
+	/**
+	Declared type of the field, could be pointer, interface, slice or map
+	*/
+	FieldType reflect.Type
+
+	/**
+	All struct tags of the field, gives ability to use custom tags together with 'inject'
+	*/
+	Tag reflect.StructTag
+
+	/**
+	Qualifier of the injection defined by 'bean=name' in the 'inject' tag, or empty string
+	*/
+	Qualifier string
+}
+
+/**
+Contextual factory bean produces non-singleton objects knowing the consumer of the object.
+
+Used for per-consumer loggers, metric scopes, named clients and so on.
+ObjectFor is called instead of Object for each injection of the non-singleton object, both on creation of context and on runtime Inject.
+Singleton factories always use Object.
+*/
+
+var ContextualFactoryBeanClass = reflect.TypeOf((*ContextualFactoryBean)(nil)).Elem()
+
+type ContextualFactoryBean interface {
+	FactoryBean
+
+	/**
+	returns an object produced by the factory for the specific injection point
+	*/
+	ObjectFor(point InjectionPoint) (interface{}, error)
+}
+
+/**
+Multi factory bean produces several beans on scan, for example from the configuration file that lists them.
+
+Each produced object is registered as its own bean with the name of the key in the map, the order if object implements OrderedBean and own lifecycle.
+Produced objects are scanned like any other instance in the scan list, could be injected in to maps and slices and have injection fields.
+Objects() is called on scan, before any injection in to the factory itself, therefore the factory should not rely on 'inject' fields in it.
+The factory is initialized before produced beans.
+*/
+
+var MultiFactoryBeanClass = reflect.TypeOf((*MultiFactoryBean)(nil)).Elem()
+
+type MultiFactoryBean interface {
+
+	/**
+	returns objects produced by the factory, where the key is the bean name of the object
+	*/
+	Objects() (map[string]interface{}, error)
+}
+
 /**
 Initializing bean context is using to run required method on post-construct injection stage
 */
@@ -265,3 +516,342 @@ type OrderedBean interface {
 	*/
 	BeanOrder() int
 }
+
+/**
+Property source provides properties for the fields with 'value' tag.
+
+Property sources are beans, all sources in the scan list are read before injection and construction of the context,
+therefore the source should not rely on 'inject' fields or PostConstruct.
+Sources are applied in the same order as beans in collections, ordered by OrderedBean first and then in scan order,
+properties of the latter source override properties of the former, properties of the context override properties of the parent context.
+Sources are read again on Context.Refresh call, sources added by Context.Register are not read.
+*/
+var PropertySourceClass = reflect.TypeOf((*PropertySource)(nil)).Elem()
+
+type PropertySource interface {
+
+	/**
+	Returns all properties of the source
+	*/
+	Properties() (map[string]string, error)
+}
+
+/**
+Secret property source marks properties that are secrets.
+Values of secret properties are redacted in Context.Properties and error messages, but injected as plain values.
+*/
+var SecretPropertySourceClass = reflect.TypeOf((*SecretPropertySource)(nil)).Elem()
+
+type SecretPropertySource interface {
+	PropertySource
+
+	/**
+	Returns true if the property is secret
+	*/
+	IsSecret(key string) bool
+}
+
+/**
+Refreshable bean gets 'value' fields bound again on Context.Refresh when properties of the context have changed
+*/
+var RefreshableBeanClass = reflect.TypeOf((*RefreshableBean)(nil)).Elem()
+
+type RefreshableBean interface {
+
+	/**
+	Runs after 'value' fields were bound again, changedKeys are sorted keys of changed, added and removed properties
+	*/
+	OnRefresh(changedKeys []string) error
+}
+
+/**
+Config validator is called after binding of the config struct and nested config structs.
+The error fails Create, Register or Refresh with the key prefix of the struct.
+*/
+var ConfigValidatorClass = reflect.TypeOf((*ConfigValidator)(nil)).Elem()
+
+type ConfigValidator interface {
+
+	/**
+	Validates bound properties
+	*/
+	Validate() error
+}
+
+/**
+Listener of the property change, value is empty if the property was removed
+*/
+type PropertyListener func(key, value string)
+
+/**
+Event publisher delivers events to EventListener beans of the context and parent contexts.
+
+Listeners of the context go first, then listeners of the parent context and so on.
+Listeners on the same level are ordered by OrderedBean, listeners without order go last in scan order.
+Only initialized beans receive events. Publisher is injectable in to any bean.
+*/
+var EventPublisherClass = reflect.TypeOf((*EventPublisher)(nil)).Elem()
+
+type EventPublisher interface {
+
+	/**
+	Delivers the event to all listeners in the current goroutine, returns errors of all failed listeners
+	*/
+	Publish(event interface{}) error
+
+	/**
+	Delivers the event to all listeners in the separate goroutine, the channel receives the result of Publish and closes
+	*/
+	PublishAsync(event interface{}) <-chan error
+}
+
+/**
+Lifecycle bean is started after all beans of the context were constructed and stopped on Close before any bean is destroyed.
+Beans are started in ascending order of PhasedBean phase and stopped in descending order,
+beans with the same phase are started in initialization order and stopped in reverse one.
+*/
+var LifecycleClass = reflect.TypeOf((*Lifecycle)(nil)).Elem()
+
+type Lifecycle interface {
+
+	/**
+	Starts the bean, called only if bean is not running
+	*/
+	Start(ctx gocontext.Context) error
+
+	/**
+	Stops the bean, called only if bean is running
+	*/
+	Stop(ctx gocontext.Context) error
+
+	/**
+	Returns true if bean is running
+	*/
+	IsRunning() bool
+}
+
+/**
+Phase of the Lifecycle bean, beans without phase have phase 0
+*/
+var PhasedBeanClass = reflect.TypeOf((*PhasedBean)(nil)).Elem()
+
+type PhasedBean interface {
+
+	/**
+	Returns the phase of the bean
+	*/
+	Phase() int
+}
+
+/**
+Service bean runs in the separate goroutine launched by the context after all beans were constructed and started.
+The context passed to Run is cancelled on Close, the context waits for Run to return before stopping Lifecycle beans and destroying beans.
+Service without SupervisedService policy is not restarted and does not close the context on failure.
+Service should not call Close of the context from Run, the failure with Escalate policy closes it instead.
+*/
+var ServiceClass = reflect.TypeOf((*Service)(nil)).Elem()
+
+type Service interface {
+
+	/**
+	Runs the service until the context is cancelled or failure
+	*/
+	Run(ctx gocontext.Context) error
+}
+
+/**
+Restart policy of the service
+*/
+type RestartPolicy int
+
+const (
+	/**
+	Service is never restarted
+	*/
+	RestartNever RestartPolicy = iota
+
+	/**
+	Service is restarted if Run returned error or panicked
+	*/
+	RestartOnFailure
+
+	/**
+	Service is restarted every time Run returned until the context is closed
+	*/
+	RestartAlways
+)
+
+func (t RestartPolicy) String() string {
+	switch t {
+	case RestartNever:
+		return "RestartNever"
+	case RestartOnFailure:
+		return "RestartOnFailure"
+	case RestartAlways:
+		return "RestartAlways"
+	default:
+		return "RestartUnknown"
+	}
+}
+
+/**
+Supervision policy of the service
+*/
+type ServicePolicy struct {
+
+	/**
+	When service is restarted
+	*/
+	Restart RestartPolicy
+
+	/**
+	Maximum number of restarts, zero means no limit
+	*/
+	MaxRestarts int
+
+	/**
+	Delay before the first restart, doubled on every next restart, DefaultBackoff if not positive
+	*/
+	Backoff time.Duration
+
+	/**
+	Maximum delay between restarts, zero means no limit
+	*/
+	MaxBackoff time.Duration
+
+	/**
+	Closes the context if service failed after all restarts
+	*/
+	Escalate bool
+}
+
+/**
+Service with supervision policy
+*/
+var SupervisedServiceClass = reflect.TypeOf((*SupervisedService)(nil)).Elem()
+
+type SupervisedService interface {
+	Service
+
+	/**
+	Returns supervision policy of the service
+	*/
+	Policy() ServicePolicy
+}
+
+/**
+State of the health check
+*/
+type HealthState string
+
+const (
+	HealthUp      HealthState = "UP"
+	HealthDown    HealthState = "DOWN"
+	HealthUnknown HealthState = "UNKNOWN"
+)
+
+/**
+Result of the health check with optional details, like latency or error
+*/
+type HealthStatus struct {
+	State   HealthState            `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+/**
+Health indicator bean checks the state of the dependency, like database connection or remote service.
+Indicators take part in readiness probe unless they implement ProbedHealthIndicator.
+*/
+var HealthIndicatorClass = reflect.TypeOf((*HealthIndicator)(nil)).Elem()
+
+type HealthIndicator interface {
+
+	/**
+	Checks the health, should return when ctx is done
+	*/
+	Health(ctx gocontext.Context) HealthStatus
+}
+
+/**
+Kind of the probe, could be combined as bit mask
+*/
+type HealthProbe int
+
+const (
+	/**
+	Probe that tells if the application is alive and should not be restarted
+	*/
+	LivenessProbe HealthProbe = 1 << iota
+
+	/**
+	Probe that tells if the application is ready to serve requests
+	*/
+	ReadinessProbe
+)
+
+func (t HealthProbe) String() string {
+	switch t {
+	case LivenessProbe:
+		return "liveness"
+	case ReadinessProbe:
+		return "readiness"
+	case LivenessProbe | ReadinessProbe:
+		return "liveness,readiness"
+	default:
+		return "none"
+	}
+}
+
+func (t HealthProbe) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *HealthProbe) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "liveness":
+		*t = LivenessProbe
+	case "readiness":
+		*t = ReadinessProbe
+	case "liveness,readiness":
+		*t = LivenessProbe | ReadinessProbe
+	case "none":
+		*t = 0
+	default:
+		return errors.Errorf("unknown health probe '%s'", text)
+	}
+	return nil
+}
+
+/**
+Health indicator with the set of probes it takes part in
+*/
+var ProbedHealthIndicatorClass = reflect.TypeOf((*ProbedHealthIndicator)(nil)).Elem()
+
+type ProbedHealthIndicator interface {
+	HealthIndicator
+
+	/**
+	Returns probes of the indicator, for example LivenessProbe | ReadinessProbe
+	*/
+	Probes() HealthProbe
+}
+
+/**
+Tracer receives spans of context operations: creation, construction of beans, PostConstruct and factory Object calls, Destroy, reload, close and runtime Inject.
+Construction spans of dependencies are children of the construction span of the dependent bean.
+Tracer is found among scanned objects and used before any bean is constructed, child contexts use tracers of parent if they have no own.
+*/
+var TracerClass = reflect.TypeOf((*Tracer)(nil)).Elem()
+
+type Tracer interface {
+
+	/**
+	Called when the operation starts, the span is the same on StartSpan and EndSpan
+	*/
+	StartSpan(span *Span)
+
+	/**
+	Called when the operation ends, End and Err are set
+	*/
+	EndSpan(span *Span)
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -30,10 +31,38 @@ type beanDef struct {
 	*/
 	anonymousFields []reflect.Type
 
+	/**
+	Indexes of anonymous fields replaced by stubs on instantiation
+	*/
+	stubFields []int
+
 	/**
 	Fields that are going to be injected
 	*/
 	fields []*injectionDef
+
+	/**
+	Fields that are going to be bound from properties
+	*/
+	properties []*propertyDef
+
+	/**
+	Config struct definition if the struct has blank field with 'config' tag
+	*/
+	config *configDef
+
+	/**
+	Type of events if bean is EventListener and the index of OnEvent method
+	*/
+	eventType   reflect.Type
+	eventMethod int
+
+	/**
+	Bean implements FactoryBean or Factory[T], for the last one the index of Object method and the type of produced object
+	*/
+	factory       bool
+	factoryMethod int
+	factoryType   reflect.Type
 }
 
 type bean struct {
@@ -97,6 +126,21 @@ type bean struct {
 	Constructor mutex for the bean
 	*/
 	ctorMu sync.Mutex
+
+	/**
+	Duration of the last PostConstruct or factory Object call
+	*/
+	initDuration time.Duration
+
+	/**
+	Context holding the bean in core, nil for beans not registered in context
+	*/
+	ctx *context
+
+	/**
+	Sequence number of the bean in scan order, keeps the order of beans stored in the core map
+	*/
+	seq uint64
 }
 
 type beanlist struct {
@@ -149,6 +193,9 @@ func (t *bean) FactoryBean() (Bean, bool) {
 }
 
 func (t *bean) Reload() error {
+	if t.ctx != nil {
+		return t.ctx.Reload(t)
+	}
 	t.ctorMu.Lock()
 	defer t.ctorMu.Unlock()
 
@@ -163,9 +210,11 @@ func (t *bean) Reload() error {
 		return errors.Errorf("bean '%s' was created by factory bean '%v and can not be reloaded", t.name, t.beenFactory.factoryClassPtr)
 	} else {
 		if init, ok := t.obj.(InitializingBean); ok {
+			start := time.Now()
 			if err := init.PostConstruct(); err != nil {
 				return err
 			}
+			t.initDuration = time.Since(start)
 		}
 	}
 	t.lifecycle = BeanInitialized
@@ -176,6 +225,21 @@ func (t *bean) Lifecycle() BeanLifecycle {
 	return t.lifecycle
 }
 
+func (t *bean) Dependencies() []Bean {
+	var list []Bean
+	for _, dep := range t.dependencies {
+		list = append(list, dep)
+	}
+	for _, dep := range t.factoryDependencies {
+		list = append(list, dep.factory.bean)
+	}
+	return list
+}
+
+func (t *bean) InitDuration() time.Duration {
+	return t.initDuration
+}
+
 /**
 Check if bean definition can implement interface type
 */
@@ -217,7 +281,17 @@ func (t *factory) String() string {
 	return t.factoryClassPtr.String()
 }
 
-func (t *factory) ctor() (*bean, bool, error) {
+/**
+Returns true if the singleton object was already produced and ctor would not call the factory
+*/
+func (t *factory) cached() bool {
+	return t.factoryBean.Singleton() && len(t.instances) > 0 && t.instances[0].obj != nil
+}
+
+/**
+Creates or gets the bean produced by factory, the injection point is nil if object is not requested by a specific field
+*/
+func (t *factory) ctor(point *InjectionPoint) (*bean, bool, error) {
 	var b *bean
 	var singleton bool
 
@@ -246,9 +320,9 @@ func (t *factory) ctor() (*bean, bool, error) {
 		}
 	}
 
-	obj, err := t.factoryBean.Object()
+	obj, err := t.produce(point)
 	if err != nil {
-		return nil, false, errors.Errorf("factory bean '%v' failed to create bean '%v', %v", t.factoryClassPtr, t.factoryBean.ObjectType(), err)
+		return nil, false, err
 	}
 
 	b.obj = obj
@@ -261,6 +335,148 @@ func (t *factory) ctor() (*bean, bool, error) {
 	return b, !singleton, nil
 }
 
+/**
+Gets the bean produced by factory for runtime injection.
+Non-singleton objects are not registered in the factory, since they are not the part of the context.
+*/
+func (t *factory) runtimeBean(point *InjectionPoint) (*bean, error) {
+	if t.factoryBean.Singleton() {
+		b, _, err := t.ctor(point)
+		return b, err
+	}
+	obj, err := t.produce(point)
+	if err != nil {
+		return nil, err
+	}
+	name := t.instances[0].name
+	if namedBean, ok := obj.(NamedBean); ok {
+		name = namedBean.BeanName()
+	}
+	return &bean{
+		name:        name,
+		beenFactory: t,
+		obj:         obj,
+		valuePtr:    reflect.ValueOf(obj),
+		beanDef:     t.instances[0].beanDef,
+		lifecycle:   BeanInitialized,
+	}, nil
+}
+
+/**
+Calls the factory and verifies that the produced object has declared type
+*/
+func (t *factory) produce(point *InjectionPoint) (interface{}, error) {
+
+	obj, err := t.object(point)
+	if err != nil {
+		return nil, errors.Errorf("factory bean '%v' failed to create bean '%v', %v", t.factoryClassPtr, t.factoryBean.ObjectType(), err)
+	}
+
+	if obj == nil {
+		return nil, errors.Errorf("factory bean '%v' produced nil object instead of '%v'", t.factoryClassPtr, t.factoryBean.ObjectType())
+	}
+
+	if objClass := reflect.TypeOf(obj); !objClass.AssignableTo(t.factoryBean.ObjectType()) {
+		return nil, errors.Errorf("factory bean '%v' produced object of type '%v' that is not assignable to declared object type '%v'", t.factoryClassPtr, objClass, t.factoryBean.ObjectType())
+	}
+
+	return obj, nil
+}
+
+func (t *factory) object(point *InjectionPoint) (interface{}, error) {
+	if point != nil && !t.factoryBean.Singleton() {
+		if contextual, ok := t.factoryBean.(ContextualFactoryBean); ok {
+			return contextual.ObjectFor(*point)
+		}
+	}
+	return t.factoryBean.Object()
+}
+
+var errorClass = reflect.TypeOf((*error)(nil)).Elem()
+
+/**
+Typed Factory[T] can not be detected by type assertion, because T is unknown on scan, use method set instead
+*/
+type typedFactory interface {
+	ObjectName() string
+	Singleton() bool
+}
+
+var typedFactoryClass = reflect.TypeOf((*typedFactory)(nil)).Elem()
+
+/**
+Adapter of Factory[T] to FactoryBean interface
+*/
+type typedFactoryBean struct {
+	typedFactory
+	object     reflect.Value
+	objectType reflect.Type
+}
+
+func (t *typedFactoryBean) Object() (interface{}, error) {
+	out := t.object.Call(nil)
+	if err, ok := out[1].Interface().(error); ok && err != nil {
+		return nil, err
+	}
+	switch out[0].Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+		if out[0].IsNil() {
+			// typed nil must not pass the nil check of the produced object
+			return nil, nil
+		}
+	}
+	return out[0].Interface(), nil
+}
+
+func (t *typedFactoryBean) ObjectType() reflect.Type {
+	return t.objectType
+}
+
+/**
+Analyzes if the type implements FactoryBean or Factory[T], where T is a pointer or interface.
+Returns the index of Object method and the type of produced object for Factory[T].
+*/
+func analyzeFactory(classPtr reflect.Type) (bool, int, reflect.Type) {
+	if classPtr.Implements(FactoryBeanClass) {
+		return true, 0, nil
+	}
+	if !classPtr.Implements(typedFactoryClass) {
+		return false, 0, nil
+	}
+	method, ok := classPtr.MethodByName("Object")
+	if !ok {
+		return false, 0, nil
+	}
+	// the receiver is the first argument of the method
+	fn := method.Type
+	if fn.NumIn() != 1 || fn.NumOut() != 2 || fn.Out(1) != errorClass {
+		return false, 0, nil
+	}
+	switch fn.Out(0).Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return true, method.Index, fn.Out(0)
+	default:
+		return false, 0, nil
+	}
+}
+
+/**
+Returns FactoryBean of the bean analyzed as FactoryBean or Factory[T], called once on scan, the factory keeps the result
+*/
+func (t *bean) asFactoryBean() (FactoryBean, bool) {
+	if !t.beanDef.factory {
+		return nil, false
+	}
+	if t.beanDef.factoryType == nil {
+		return t.obj.(FactoryBean), true
+	}
+	return &typedFactoryBean{
+		typedFactory: t.obj.(typedFactory),
+		object:       t.valuePtr.Method(t.beanDef.factoryMethod),
+		objectType:   t.beanDef.factoryType,
+	}, true
+}
+
 type factoryDependency struct {
 
 	/*
@@ -269,6 +485,11 @@ type factoryDependency struct {
 
 	factory *factory
 
+	/*
+		Field where produced instance is going to be injected
+	*/
+	point *InjectionPoint
+
 	/*
 		Injection function where we need to inject produced instance
 	*/
@@ -279,36 +500,31 @@ type factoryDependency struct {
 Investigate bean by using reflection
 */
 func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
+	def, err := analyze(classPtr)
+	if err != nil {
+		return nil, err
+	}
+	return instantiate(obj, def), nil
+}
+
+/**
+Analyzes the type of the bean, the definition does not depend on the instance and could be reused
+*/
+func analyze(classPtr reflect.Type) (*beanDef, error) {
 	var fields []*injectionDef
+	var properties []*propertyDef
+	var config *configDef
+	var err error
 	var anonymousFields []reflect.Type
-	valuePtr := reflect.ValueOf(obj)
-	value := valuePtr.Elem()
+	var stubFields []int
 	class := classPtr.Elem()
 	for j := 0; j < class.NumField(); j++ {
 		field := class.Field(j)
 		if field.Anonymous {
 			anonymousFields = append(anonymousFields, field.Type)
 			switch field.Type {
-			case NamedBeanClass:
-				stub := &namedBeanStub{name: classPtr.String()}
-				stubValuePtr := reflect.ValueOf(stub)
-				value.Field(j).Set(stubValuePtr)
-			case OrderedBeanClass:
-				stub := &orderedBeanStub{}
-				stubValuePtr := reflect.ValueOf(stub)
-				value.Field(j).Set(stubValuePtr)
-			case InitializingBeanClass:
-				stub := &initializingBeanStub{name: classPtr.String()}
-				stubValuePtr := reflect.ValueOf(stub)
-				value.Field(j).Set(stubValuePtr)
-			case DisposableBeanClass:
-				stub := &disposableBeanStub{name: classPtr.String()}
-				stubValuePtr := reflect.ValueOf(stub)
-				value.Field(j).Set(stubValuePtr)
-			case FactoryBeanClass:
-				stub := &factoryBeanStub{name: classPtr.String(), elemType: classPtr}
-				stubValuePtr := reflect.ValueOf(stub)
-				value.Field(j).Set(stubValuePtr)
+			case NamedBeanClass, OrderedBeanClass, InitializingBeanClass, DisposableBeanClass, FactoryBeanClass:
+				stubFields = append(stubFields, j)
 			case ContextClass:
 				return nil, errors.Errorf("exposing by anonymous field '%s' in '%v' interface beans.Context is not allowed", field.Name, classPtr)
 			}
@@ -321,6 +537,7 @@ func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
 			var qualifier string
 			var optional bool
 			var lazy bool
+			var dynamic bool
 			level := DefaultLevel
 			if hasInjectTag {
 				pairs := strings.Split(injectTag, ",")
@@ -336,6 +553,8 @@ func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
 						optional = true
 					case "lazy":
 						lazy = true
+					case "dynamic":
+						dynamic = true
 					case "level":
 						if len(kv) > 1 {
 							level, _ = strconv.Atoi(kv[1])
@@ -359,6 +578,9 @@ func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
 				fieldType = field.Type.Elem()
 				kind = fieldType.Kind()
 			}
+			if dynamic && !fieldSlice && !fieldMap {
+				return nil, errors.Errorf("dynamic injection is supported only for slice or map, but field type is '%v' on position %d in %v with 'inject' tag", field.Type, j, classPtr)
+			}
 			if kind != reflect.Ptr && kind != reflect.Interface && kind != reflect.Func {
 				return nil, errors.Errorf("not a pointer, interface or function field type '%v' on position %d in %v with 'inject' tag", field.Type, j, classPtr)
 			}
@@ -367,7 +589,9 @@ func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
 				fieldNum:  j,
 				fieldName: field.Name,
 				fieldType: fieldType,
+				tag:       field.Tag,
 				lazy:      lazy,
+				dynamic:   dynamic,
 				slice:     fieldSlice,
 				table:     fieldMap,
 				optional:  optional,
@@ -376,6 +600,68 @@ func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
 			}
 			fields = append(fields, injectDef)
 		}
+		if configTag, hasConfigTag := field.Tag.Lookup("config"); hasConfigTag && field.Name == "_" {
+			config, err = parseConfigDef(field, j, configTag, classPtr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if valueTag, hasValueTag := field.Tag.Lookup("value"); hasValueTag {
+			propertyDef, err := parsePropertyDef(field, j, valueTag, classPtr)
+			if err != nil {
+				return nil, err
+			}
+			properties = append(properties, propertyDef)
+		}
+	}
+	var eventType reflect.Type
+	var eventMethod int
+	if method, ok := classPtr.MethodByName("OnEvent"); ok {
+		fn := method.Type
+		if fn.NumIn() == 2 && fn.NumOut() == 1 && fn.Out(0) == errorClass {
+			eventType = fn.In(1)
+			eventMethod = method.Index
+		}
+	}
+	factory, factoryMethod, factoryType := analyzeFactory(classPtr)
+	return &beanDef{
+		classPtr:        classPtr,
+		anonymousFields: anonymousFields,
+		stubFields:      stubFields,
+		fields:          fields,
+		properties:      properties,
+		config:          config,
+		eventType:       eventType,
+		eventMethod:     eventMethod,
+		factory:         factory,
+		factoryMethod:   factoryMethod,
+		factoryType:     factoryType,
+	}, nil
+}
+
+/**
+Creates the bean of the object by the definition of its type, sets stubs in to anonymous fields
+*/
+func instantiate(obj interface{}, def *beanDef) *bean {
+	classPtr := def.classPtr
+	valuePtr := reflect.ValueOf(obj)
+	value := valuePtr.Elem()
+	class := classPtr.Elem()
+	for _, j := range def.stubFields {
+		var stub interface{}
+		switch class.Field(j).Type {
+		case NamedBeanClass:
+			stub = &namedBeanStub{name: classPtr.String()}
+		case OrderedBeanClass:
+			stub = &orderedBeanStub{}
+		case InitializingBeanClass:
+			stub = &initializingBeanStub{name: classPtr.String()}
+		case DisposableBeanClass:
+			stub = &disposableBeanStub{name: classPtr.String()}
+		case FactoryBeanClass:
+			stub = &factoryBeanStub{name: classPtr.String(), elemType: classPtr}
+		}
+		value.Field(j).Set(reflect.ValueOf(stub))
 	}
 	name := classPtr.String()
 	var qualifier string
@@ -390,19 +676,15 @@ func investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
 		order = orderedBean.BeanOrder()
 	}
 	return &bean{
-		name:     name,
+		name:      name,
 		qualifier: qualifier,
-		ordered:  ordered,
-		order:    order,
-		obj:      obj,
-		valuePtr: valuePtr,
-		beanDef: &beanDef{
-			classPtr:        classPtr,
-			anonymousFields: anonymousFields,
-			fields:          fields,
-		},
+		ordered:   ordered,
+		order:     order,
+		obj:       obj,
+		valuePtr:  valuePtr,
+		beanDef:   def,
 		lifecycle: BeanCreated,
-	}, nil
+	}
 }
 
 func isSomeoneImplements(iface reflect.Type, list []reflect.Type) bool {
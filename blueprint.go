@@ -0,0 +1,239 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+)
+
+/**
+Compiled context definition, creates contexts of the same types without analyzing them again.
+Use it when the same set of beans is created many times, for example the context per tenant or per request.
+*/
+type Blueprint interface {
+
+	/**
+	Creates the new context from instances of compiled types in the same order as given to Compile.
+	Without instances the blueprint creates zero values of compiled types and reuses compiled functions.
+	*/
+	New(instances ...interface{}) (Context, error)
+
+	/**
+	Creates the child context of the parent like New
+	*/
+	Extend(parent Context, instances ...interface{}) (Context, error)
+}
+
+type blueprint struct {
+
+	/**
+	Scanned objects given to Compile in scan order
+	*/
+	prototypes []interface{}
+
+	/**
+	Types of scanned objects in scan order
+	*/
+	types []reflect.Type
+
+	/**
+	Analyzed definitions by type of the bean
+	*/
+	beanDefs map[reflect.Type]*beanDef
+
+	/**
+	All types of the context including types produced by factories
+	*/
+	coreTypes map[reflect.Type]bool
+
+	/**
+	Types of the context implementing injected interfaces
+	*/
+	plan map[reflect.Type][]reflect.Type
+}
+
+/**
+Compiles the context definition by scanning objects like Create does: analyzes types of beans and resolves interface injections between them.
+Objects are used only to get types, they are not part of created contexts and are not changed, the scan works with their shallow copies.
+Methods ObjectType, ObjectName and Singleton of FactoryBean are called on copies, MultiFactoryBean is never called,
+contexts with objects produced by multi factories search interfaces like Create does.
+*/
+func Compile(scan ...interface{}) (Blueprint, error) {
+
+	t := &blueprint{
+		beanDefs:  make(map[reflect.Type]*beanDef),
+		coreTypes: make(map[reflect.Type]bool),
+		plan:      make(map[reflect.Type][]reflect.Type),
+	}
+
+	err := forEach("", scan, func(pos string, obj interface{}) error {
+		t.prototypes = append(t.prototypes, obj)
+		t.types = append(t.types, reflect.TypeOf(obj))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	core := map[reflect.Type][]*bean{
+		contextClassPtr: {{beanDef: &beanDef{classPtr: contextClassPtr}}},
+	}
+
+	defs := newDefinitions(core)
+	defs.compile = true
+	err = forEach("", t.prototypes, func(pos string, obj interface{}) error {
+		return defs.scanObject(pos, shallowCopy(obj), "", nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var classes []reflect.Type
+	for classPtr, list := range core {
+		t.coreTypes[classPtr] = true
+		classes = append(classes, classPtr)
+		for _, b := range list {
+			if b.obj != nil && b.beenFactory == nil && classPtr.Kind() == reflect.Ptr {
+				t.beanDefs[classPtr] = b.beanDef
+			}
+		}
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].String() < classes[j].String()
+	})
+
+	for ifaceType := range defs.interfaces {
+		var list []reflect.Type
+		for _, classPtr := range classes {
+			if isCandidate(classPtr, core[classPtr], ifaceType) {
+				list = append(list, classPtr)
+			}
+		}
+		t.plan[ifaceType] = list
+	}
+
+	return t, nil
+}
+
+func (t *blueprint) New(instances ...interface{}) (Context, error) {
+	scan, err := t.instances(instances)
+	if err != nil {
+		return nil, err
+	}
+	return createContext(nil, scan, createOptions{blueprint: t})
+}
+
+func (t *blueprint) Extend(parent Context, instances ...interface{}) (Context, error) {
+	p, ok := parent.(*context)
+	if !ok {
+		return nil, errors.Errorf("parent context '%v' is not created by beans", reflect.TypeOf(parent))
+	}
+	scan, err := t.instances(instances)
+	if err != nil {
+		return nil, err
+	}
+	return createContext(p, scan, createOptions{blueprint: t})
+}
+
+/**
+Returns the copy of the struct, so the scan does not set stubs in to anonymous fields of the object given by the caller
+*/
+func shallowCopy(obj interface{}) interface{} {
+	valuePtr := reflect.ValueOf(obj)
+	if valuePtr.Kind() != reflect.Ptr || valuePtr.IsNil() || valuePtr.Elem().Kind() != reflect.Struct {
+		return obj
+	}
+	copyPtr := reflect.New(valuePtr.Elem().Type())
+	copyPtr.Elem().Set(valuePtr.Elem())
+	return copyPtr.Interface()
+}
+
+/**
+Checks types of instances or creates them if not given
+*/
+func (t *blueprint) instances(instances []interface{}) ([]interface{}, error) {
+
+	if len(instances) == 0 {
+		scan := make([]interface{}, len(t.types))
+		for i, classPtr := range t.types {
+			if classPtr.Kind() == reflect.Ptr {
+				scan[i] = reflect.New(classPtr.Elem()).Interface()
+			} else {
+				scan[i] = t.prototypes[i]
+			}
+		}
+		return scan, nil
+	}
+
+	var scan []interface{}
+	err := forEach("", instances, func(pos string, obj interface{}) error {
+		scan = append(scan, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scan) != len(t.types) {
+		return nil, errors.Errorf("blueprint expects %d instances, but was %d", len(t.types), len(scan))
+	}
+	for i, obj := range scan {
+		if classPtr := reflect.TypeOf(obj); classPtr != t.types[i] {
+			return nil, errors.Errorf("instance on position %d has type '%v', but blueprint expects '%v'", i, classPtr, t.types[i])
+		}
+	}
+	return scan, nil
+}
+
+/**
+Checks that the context has the same types as compiled, factories could produce other types or objects for each context
+*/
+func (t *blueprint) sameTypes(core map[reflect.Type][]*bean) bool {
+	if len(core) != len(t.coreTypes) {
+		return false
+	}
+	for classPtr := range core {
+		if !t.coreTypes[classPtr] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+Investigates the bean by using the compiled definition of its type if exist
+*/
+func (t *definitions) investigate(obj interface{}, classPtr reflect.Type) (*bean, error) {
+	if t.blueprint != nil {
+		if def, ok := t.blueprint.beanDefs[classPtr]; ok {
+			return instantiate(obj, def), nil
+		}
+	}
+	return investigate(obj, classPtr)
+}
+
+/**
+Finds candidates of the interface by the compiled plan in the context and by search in parents
+*/
+func (t *context) plannedCandidatesRecursive(ifaceType reflect.Type, plan []reflect.Type) []beanlist {
+	var candidates []beanlist
+	t.coreMu.RLock()
+	var list []*bean
+	for _, classPtr := range plan {
+		list = append(list, t.core[classPtr]...)
+	}
+	t.coreMu.RUnlock()
+	if len(list) > 0 {
+		candidates = append(candidates, beanlist{level: 1, list: list})
+	}
+	if t.parent != nil {
+		for _, entry := range t.parent.searchCandidatesRecursive(ifaceType) {
+			candidates = append(candidates, beanlist{level: entry.level + 1, list: entry.list})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,227 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"testing"
+)
+
+type bpRepository interface {
+	Find(id string) string
+}
+
+type bpLog struct {
+}
+
+type bpRepositoryImpl struct {
+	Log *bpLog `inject`
+}
+
+func (t *bpRepositoryImpl) Find(id string) string {
+	return id
+}
+
+type bpService struct {
+	Repository bpRepository   `inject`
+	All        []bpRepository `inject`
+	Log        *bpLog         `inject`
+	Ctx        beans.Context  `inject`
+}
+
+var bpServiceClass = reflect.TypeOf((*bpService)(nil))
+
+func serviceOf(t *testing.T, ctx beans.Context) *bpService {
+	list := ctx.Bean(bpServiceClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	return list[0].Object().(*bpService)
+}
+
+type bpOtherRepository struct {
+}
+
+func (t *bpOtherRepository) Find(id string) string {
+	return "other"
+}
+
+func TestBlueprint(t *testing.T) {
+
+	bp, err := beans.Compile(&bpLog{}, &bpRepositoryImpl{}, &bpService{})
+	require.NoError(t, err)
+
+	ctx, err := bp.New()
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	service := serviceOf(t, ctx)
+	require.NotNil(t, service.Repository)
+	require.Equal(t, 1, len(service.All))
+	require.True(t, service.Ctx == ctx)
+	require.True(t, service.Repository.(*bpRepositoryImpl).Log == service.Log)
+
+	other, err := bp.New()
+	require.NoError(t, err)
+	defer other.Close()
+
+	require.False(t, serviceOf(t, other) == service)
+
+	log := &bpLog{}
+	repository := &bpRepositoryImpl{}
+	service = &bpService{}
+	ctx, err = bp.New(log, []interface{}{repository, service})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.True(t, service.Repository == repository)
+	require.True(t, repository.Log == log)
+}
+
+func TestBlueprintExtend(t *testing.T) {
+
+	parent, err := beans.Create(&bpLog{}, &bpOtherRepository{})
+	require.NoError(t, err)
+	defer parent.Close()
+
+	bp, err := beans.Compile(&bpRepositoryImpl{}, &bpService{})
+	require.NoError(t, err)
+
+	ctx, err := bp.Extend(parent)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	service := serviceOf(t, ctx)
+	require.Equal(t, "id", service.Repository.Find("id"))
+	require.IsType(t, &bpRepositoryImpl{}, service.Repository)
+	require.Equal(t, 1, len(service.All))
+	require.NotNil(t, service.Log)
+
+	// the same definition without the blueprint
+	expected, err := parent.Extend(&bpRepositoryImpl{}, &bpService{})
+	require.NoError(t, err)
+	defer expected.Close()
+
+	require.Equal(t, len(serviceOf(t, expected).All), len(service.All))
+}
+
+func TestBlueprintErrors(t *testing.T) {
+
+	bp, err := beans.Compile(&bpLog{}, &bpRepositoryImpl{})
+	require.NoError(t, err)
+
+	_, err = bp.New(&bpLog{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "blueprint expects 2 instances, but was 1")
+
+	_, err = bp.New(&bpLog{}, &bpOtherRepository{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "instance on position 1 has type '*beans_test.bpOtherRepository', but blueprint expects '*beans_test.bpRepositoryImpl'")
+
+	bp, err = beans.Compile(&bpService{})
+	require.NoError(t, err)
+
+	_, err = bp.New()
+	require.Error(t, err)
+}
+
+var bpFactoryCalls int
+
+type bpRepositoryFactory struct {
+}
+
+func (t *bpRepositoryFactory) Objects() (map[string]interface{}, error) {
+	bpFactoryCalls++
+	return map[string]interface{}{
+		"main":  &bpRepositoryImpl{},
+		"other": &bpOtherRepository{},
+	}, nil
+}
+
+type bpCollector struct {
+	All []bpRepository `inject`
+}
+
+func TestBlueprintFallback(t *testing.T) {
+
+	bpFactoryCalls = 0
+
+	bp, err := beans.Compile(&bpLog{}, &bpRepositoryFactory{}, &bpCollector{})
+	require.NoError(t, err)
+	require.Equal(t, 0, bpFactoryCalls)
+
+	collector := &bpCollector{}
+	ctx, err := bp.New(&bpLog{}, &bpRepositoryFactory{}, collector)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	// factory produced types that are not compiled, interfaces are searched like on Create
+	require.Equal(t, 1, bpFactoryCalls)
+	require.Equal(t, 2, len(collector.All))
+}
+
+/**
+Creation of the child context per tenant or request
+*/
+func BenchmarkBlueprint(b *testing.B) {
+
+	parent, err := beans.Create(&bpLog{}, &bpOtherRepository{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer parent.Close()
+
+	b.Run("extend", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ctx, err := parent.Extend(&bpRepositoryImpl{}, &bpService{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			ctx.Close()
+		}
+	})
+
+	bp, err := beans.Compile(&bpRepositoryImpl{}, &bpService{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("blueprint", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ctx, err := bp.Extend(parent)
+			if err != nil {
+				b.Fatal(err)
+			}
+			ctx.Close()
+		}
+	})
+}
+
+type bpNamed struct {
+	beans.NamedBean
+	Log *bpLog `inject`
+}
+
+func TestBlueprintPrototypes(t *testing.T) {
+
+	prototype := &bpNamed{}
+	bp, err := beans.Compile(&bpLog{}, prototype)
+	require.NoError(t, err)
+
+	// the scan does not set stubs or fields of objects given to Compile
+	require.Nil(t, prototype.NamedBean)
+	require.Nil(t, prototype.Log)
+
+	ctx, err := bp.New()
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(reflect.TypeOf(prototype), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	require.False(t, list[0].Object() == prototype)
+	require.NotNil(t, list[0].Object().(*bpNamed).Log)
+}
@@ -0,0 +1,52 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Command beans prints the wiring graph of contexts created in packages with problems found without running the program, see package graph.
+The exit code is 1 if the graph has errors, or warnings with -strict flag, so it could be used in CI.
+
+Example:
+	go run go.arpabet.com/beans/cmd/beans ./...
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go.arpabet.com/beans/cmd/graph"
+	"os"
+)
+
+func main() {
+
+	strict := flag.Bool("strict", false, "fail on warnings")
+	dir := flag.String("dir", "", "directory to load packages from")
+	tests := flag.Bool("tests", false, "include contexts created in test files")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: beans [-strict] [-tests] [-dir=path] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	g, err := graph.Load(*dir, *tests, patterns...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "beans: %v\n", err)
+		os.Exit(2)
+	}
+
+	if err := g.Write(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "beans: %v\n", err)
+		os.Exit(2)
+	}
+
+	errors, warnings := g.Count()
+	if errors > 0 || (*strict && warnings > 0) {
+		os.Exit(1)
+	}
+}
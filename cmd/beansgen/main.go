@@ -0,0 +1,63 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Command beansgen generates plain Go code that allocates beans, sets their 'inject' fields and creates the context by beans.Wired,
+so missing or ambiguous dependencies fail the generation instead of the startup.
+Bean types are not discovered, flag -types is required and lists all beans of the package in scan order.
+
+Example:
+	//go:generate go run go.arpabet.com/beans/cmd/beansgen -types=UserService,UserStorage -func=newContext
+
+The generated function has the signature 'func newContext() (beans.Context, error)', see package gen for supported injections.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go.arpabet.com/beans/cmd/gen"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+
+	types := flag.String("types", "", "required, comma separated names of bean types in the package, the order of the scan")
+	funcName := flag.String("func", "newContext", "name of the generated function")
+	output := flag.String("output", "beans_gen.go", "name of the generated file in the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: beansgen -types=A,B [-func=newContext] [-output=beans_gen.go] [package]\n")
+		fmt.Fprintf(os.Stderr, "Bean types are not discovered in the package, flag -types lists all of them explicitly.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pattern := "."
+	if flag.NArg() > 0 {
+		pattern = flag.Arg(0)
+	}
+
+	generator := &gen.Generator{
+		Types: strings.Split(*types, ","),
+		Func:  *funcName,
+	}
+
+	code, dir, err := generator.Generate(pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "beansgen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, *output), code, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "beansgen: %v\n", err)
+		os.Exit(1)
+	}
+}
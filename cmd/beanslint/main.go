@@ -0,0 +1,22 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Command beanslint runs the analyzer of beans structs, see package lint.
+
+Example:
+	go install go.arpabet.com/beans/cmd/beanslint
+	beanslint ./...
+	go vet -vettool=$(which beanslint) ./...
+*/
+package main
+
+import (
+	"go.arpabet.com/beans/cmd/lint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(lint.Analyzer)
+}
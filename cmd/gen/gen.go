@@ -0,0 +1,321 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Package gen generates plain Go code that allocates beans, sets their 'inject' fields and creates the context by beans.Wired,
+so missing or ambiguous dependencies fail the generation instead of the startup.
+
+Supported injections are pointers, interfaces and slices of them with 'optional' and 'dynamic' options.
+Qualifiers, maps, lazy functions, factory beans and ordered beans in slices are resolved on runtime, so they fail the generation.
+Fields of type beans.Context are set by beans.Wired.
+*/
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"go/format"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+/**
+Import path of the beans package
+*/
+const beansPath = "go.arpabet.com/beans"
+
+/**
+Build tag excluding generated files while loading the package, so the outdated code does not break the generation
+*/
+const BuildTag = "beansgen"
+
+/**
+Generator of the wiring code for bean types of one package
+*/
+type Generator struct {
+
+	/**
+	Names of bean types in the package in scan order, required, since types of the package are not discovered
+	*/
+	Types []string
+
+	/**
+	Name of the generated function
+	*/
+	Func string
+}
+
+/**
+Bean allocated by the generated code
+*/
+type genBean struct {
+	name     string
+	typeName string
+	ptr      types.Type
+}
+
+/**
+Assignment of beans to the field
+*/
+type genField struct {
+	target *genBean
+	name   string
+	slice  bool
+	beans  []*genBean
+}
+
+/**
+Loads the package, resolves 'inject' fields of bean types and returns the formatted code with the directory of the package
+*/
+func (t *Generator) Generate(pattern string) ([]byte, string, error) {
+
+	cfg := &packages.Config{
+		Mode:       packages.NeedName | packages.NeedFiles | packages.NeedTypes,
+		BuildFlags: []string{"-tags=" + BuildTag},
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(pkgs) != 1 {
+		return nil, "", errors.Errorf("pattern '%s' matches %d packages, expected one", pattern, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, "", errors.Errorf("package '%s' has errors, %v", pkg.PkgPath, pkg.Errors[0])
+	}
+	if len(pkg.GoFiles) == 0 {
+		return nil, "", errors.Errorf("package '%s' has no go files", pkg.PkgPath)
+	}
+
+	code, err := t.generate(pkg.Types)
+	if err != nil {
+		return nil, "", err
+	}
+	return code, filepath.Dir(pkg.GoFiles[0]), nil
+}
+
+func (t *Generator) generate(pkg *types.Package) ([]byte, error) {
+
+	if !token.IsIdentifier(t.Func) {
+		return nil, errors.Errorf("function name '%s' is not an identifier", t.Func)
+	}
+
+	used := map[string]bool{"beans": true, t.Func: true}
+	for _, typeName := range t.Types {
+		used[strings.TrimSpace(typeName)] = true
+	}
+	var list []*genBean
+	for _, typeName := range t.Types {
+		typeName = strings.TrimSpace(typeName)
+		obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok {
+			return nil, errors.Errorf("type '%s' not found in package '%s'", typeName, pkg.Path())
+		}
+		if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+			return nil, errors.Errorf("type '%s' in package '%s' is not a struct", typeName, pkg.Path())
+		}
+		ptr := types.NewPointer(obj.Type())
+		if isFactoryBean(ptr) {
+			return nil, errors.Errorf("factory bean '%s' is not supported, it produces objects on runtime", typeName)
+		}
+		list = append(list, &genBean{
+			name:     varName(typeName, used),
+			typeName: typeName,
+			ptr:      ptr,
+		})
+	}
+
+	var fields []*genField
+	for _, b := range list {
+		st := b.ptr.(*types.Pointer).Elem().Underlying().(*types.Struct)
+		for i := 0; i < st.NumFields(); i++ {
+			f, err := resolveField(b, st.Field(i), reflect.StructTag(st.Tag(i)), list)
+			if err != nil {
+				return nil, err
+			}
+			if f != nil {
+				fields = append(fields, f)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by beansgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "//go:build !%s\n\n", BuildTag)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg.Name())
+	fmt.Fprintf(&buf, "import %q\n\n", beansPath)
+	fmt.Fprintf(&buf, "// %s creates the context of wired beans %s.\n", t.Func, strings.Join(t.Types, ", "))
+	fmt.Fprintf(&buf, "func %s() (beans.Context, error) {\n", t.Func)
+	for _, b := range list {
+		fmt.Fprintf(&buf, "%s := &%s{}\n", b.name, b.typeName)
+	}
+	for _, f := range fields {
+		var names []string
+		for _, dep := range f.beans {
+			names = append(names, dep.name)
+		}
+		if f.slice {
+			fmt.Fprintf(&buf, "%s.%s = append(%s.%s, %s)\n", f.target.name, f.name, f.target.name, f.name, strings.Join(names, ", "))
+		} else {
+			fmt.Fprintf(&buf, "%s.%s = %s\n", f.target.name, f.name, names[0])
+		}
+	}
+	var names []string
+	for _, b := range list {
+		names = append(names, b.name)
+	}
+	fmt.Fprintf(&buf, "return beans.Wired(%s)\n", strings.Join(names, ", "))
+	fmt.Fprintf(&buf, "}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+/**
+Finds beans for the field with 'inject' tag, returns nil if the field is not injected or set by beans.Wired
+*/
+func resolveField(b *genBean, field *types.Var, tag reflect.StructTag, list []*genBean) (*genField, error) {
+
+	injectTag, hasInjectTag := tag.Lookup("inject")
+	if tag != "inject" && !hasInjectTag {
+		return nil, nil
+	}
+
+	if field.Anonymous() {
+		return nil, errors.Errorf("injection to anonymous field '%s' in '%s' is not allowed", field.Name(), b.typeName)
+	}
+	if !field.Exported() {
+		return nil, errors.Errorf("field '%s' in '%s' is not public", field.Name(), b.typeName)
+	}
+
+	var optional bool
+	if hasInjectTag {
+		for _, pair := range strings.Split(injectTag, ",") {
+			kv := strings.Split(strings.TrimSpace(pair), "=")
+			switch strings.TrimSpace(kv[0]) {
+			case "optional":
+				optional = true
+			case "dynamic", "level", "":
+			case "bean", "lazy":
+				return nil, errors.Errorf("option '%s' of field '%s' in '%s' is resolved on runtime and not supported", kv[0], field.Name(), b.typeName)
+			default:
+				return nil, errors.Errorf("unknown option '%s' of field '%s' in '%s'", strings.TrimSpace(kv[0]), field.Name(), b.typeName)
+			}
+		}
+	}
+
+	fieldType := field.Type()
+	var slice bool
+	switch typ := fieldType.Underlying().(type) {
+	case *types.Slice:
+		slice = true
+		fieldType = typ.Elem()
+	case *types.Map:
+		return nil, errors.Errorf("map field '%s' in '%s' is keyed by bean names on runtime and not supported", field.Name(), b.typeName)
+	}
+
+	if isContext(fieldType) && !slice {
+		return nil, nil
+	}
+
+	var candidates []*genBean
+	switch typ := fieldType.Underlying().(type) {
+	case *types.Pointer:
+		for _, c := range list {
+			if types.Identical(c.ptr, fieldType) {
+				candidates = append(candidates, c)
+			}
+		}
+	case *types.Interface:
+		for _, c := range list {
+			if types.Implements(c.ptr, typ) {
+				candidates = append(candidates, c)
+			}
+		}
+	default:
+		return nil, errors.Errorf("not a pointer, interface or slice field type '%s' of field '%s' in '%s'", field.Type(), field.Name(), b.typeName)
+	}
+
+	if len(candidates) == 0 {
+		if optional {
+			return nil, nil
+		}
+		return nil, errors.Errorf("can not find candidates to inject the required field '%s' in '%s' of type '%s'", field.Name(), b.typeName, field.Type())
+	}
+
+	if slice {
+		for _, c := range candidates {
+			if hasMethod(c.ptr, "BeanOrder") {
+				return nil, errors.Errorf("ordered bean '%s' in slice field '%s' in '%s' is ordered on runtime and not supported", c.typeName, field.Name(), b.typeName)
+			}
+		}
+	} else if len(candidates) > 1 {
+		var names []string
+		for _, c := range candidates {
+			names = append(names, c.typeName)
+		}
+		return nil, errors.Errorf("field '%s' in '%s' can not be injected with multiple candidates %s", field.Name(), b.typeName, strings.Join(names, ", "))
+	}
+
+	return &genField{
+		target: b,
+		name:   field.Name(),
+		slice:  slice,
+		beans:  candidates,
+	}, nil
+}
+
+/**
+Checks if the type is beans.Context
+*/
+func isContext(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == beansPath && obj.Name() == "Context"
+}
+
+/**
+Checks if the type implements beans.FactoryBean or beans.Factory[T] by method names
+*/
+func isFactoryBean(typ types.Type) bool {
+	for _, name := range []string{"Object", "ObjectName", "Singleton"} {
+		if !hasMethod(typ, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasMethod(typ types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+/**
+Returns unique variable name for the bean type
+*/
+func varName(typeName string, used map[string]bool) string {
+	r := []rune(typeName)
+	r[0] = unicode.ToLower(r[0])
+	base := string(r)
+	if token.IsKeyword(base) {
+		base += "Bean"
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,90 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package gen_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans/cmd/gen"
+	"go.arpabet.com/beans/cmd/gen/internal/example"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+
+	generator := &gen.Generator{
+		Types: []string{"UserService", "UserStorage", "AuditLog", "Handlers"},
+		Func:  "NewContext",
+	}
+
+	code, dir, err := generator.Generate("./internal/example")
+	require.NoError(t, err)
+
+	abs, err := filepath.Abs("internal/example")
+	require.NoError(t, err)
+	require.Equal(t, abs, dir)
+
+	expected, err := os.ReadFile(filepath.Join(dir, "beans_gen.go"))
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(code), "run go generate ./... in the cmd module")
+}
+
+func TestGeneratedContext(t *testing.T) {
+
+	ctx, err := example.NewContext()
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(reflect.TypeOf((*example.UserService)(nil)), 0)
+	require.Equal(t, 1, len(list))
+	service := list[0].Object().(*example.UserService)
+
+	require.Equal(t, "user 1", service.Storage.Load("1"))
+	require.NotNil(t, service.Audit)
+	require.Nil(t, service.Cache)
+
+	storage := service.Storage.(*example.UserStorage)
+	require.True(t, storage.Ctx == ctx)
+
+	list = ctx.Lookup("*example.UserStorage", 0)
+	require.Equal(t, 1, len(list))
+	require.True(t, list[0].Object() == storage)
+
+	handlers := ctx.Bean(reflect.TypeOf((*example.Handlers)(nil)), 0)[0].Object().(*example.Handlers)
+	require.Equal(t, 2, len(handlers.List))
+	require.Equal(t, "storage", handlers.List[0].Handle())
+	require.Equal(t, "audit", handlers.List[1].Handle())
+
+	deps := ctx.Bean(reflect.TypeOf((*example.Handlers)(nil)), 0)[0].Dependencies()
+	require.Equal(t, 2, len(deps))
+}
+
+func TestGenerateErrors(t *testing.T) {
+
+	cases := []struct {
+		types []string
+		err   string
+	}{
+		{[]string{"Service"}, "can not find candidates to inject the required field 'Storage' in 'Service'"},
+		{[]string{"Service", "FileStorage", "MemoryStorage"}, "can not be injected with multiple candidates FileStorage, MemoryStorage"},
+		{[]string{"CachedService", "FileStorage"}, "can not find candidates to inject the required field 'Cache'"},
+		{[]string{"NamedService", "FileStorage"}, "option 'bean' of field 'Storage' in 'NamedService' is resolved on runtime"},
+		{[]string{"TypoService", "FileStorage"}, "unknown option 'optinal' of field 'Storage' in 'TypoService'"},
+		{[]string{"TableService", "FileStorage"}, "map field 'Storages' in 'TableService'"},
+		{[]string{"PrivateService", "FileStorage"}, "field 'storage' in 'PrivateService' is not public"},
+		{[]string{"Storage"}, "type 'Storage' in package 'go.arpabet.com/beans/cmd/gen/testdata/invalid' is not a struct"},
+		{[]string{"Unknown"}, "type 'Unknown' not found"},
+		{[]string{"ClientFactory"}, "factory bean 'ClientFactory' is not supported"},
+	}
+
+	for _, c := range cases {
+		generator := &gen.Generator{Types: c.types, Func: "newContext"}
+		_, _, err := generator.Generate("./testdata/invalid")
+		require.Error(t, err, c.types)
+		require.Contains(t, err.Error(), c.err)
+	}
+}
@@ -0,0 +1,19 @@
+// Code generated by beansgen. DO NOT EDIT.
+
+//go:build !beansgen
+
+package example
+
+import "go.arpabet.com/beans"
+
+// NewContext creates the context of wired beans UserService, UserStorage, AuditLog, Handlers.
+func NewContext() (beans.Context, error) {
+	userService := &UserService{}
+	userStorage := &UserStorage{}
+	auditLog := &AuditLog{}
+	handlers := &Handlers{}
+	userService.Storage = userStorage
+	userService.Audit = auditLog
+	handlers.List = append(handlers.List, userStorage, auditLog)
+	return beans.Wired(userService, userStorage, auditLog, handlers)
+}
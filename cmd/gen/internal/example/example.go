@@ -0,0 +1,50 @@
+/**
+Package example has beans wired by beansgen, the generated code is checked by tests of package gen
+*/
+package example
+
+import "go.arpabet.com/beans"
+
+//go:generate go run go.arpabet.com/beans/cmd/beansgen -types=UserService,UserStorage,AuditLog,Handlers -func=NewContext
+
+type Storage interface {
+	Load(id string) string
+}
+
+type Handler interface {
+	Handle() string
+}
+
+type Cache interface {
+	Get(key string) string
+}
+
+type UserStorage struct {
+	Ctx beans.Context `inject`
+}
+
+func (t *UserStorage) Load(id string) string {
+	return "user " + id
+}
+
+func (t *UserStorage) Handle() string {
+	return "storage"
+}
+
+type AuditLog struct {
+	Entries []string
+}
+
+func (t *AuditLog) Handle() string {
+	return "audit"
+}
+
+type UserService struct {
+	Storage Storage   `inject`
+	Audit   *AuditLog `inject:"optional"`
+	Cache   Cache     `inject:"optional"`
+}
+
+type Handlers struct {
+	List []Handler `inject`
+}
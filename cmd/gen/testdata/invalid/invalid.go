@@ -0,0 +1,49 @@
+package invalid
+
+type Storage interface {
+	Load(id string) string
+}
+
+type Cache interface {
+	Get(key string) string
+}
+
+type FileStorage struct{}
+
+func (t *FileStorage) Load(id string) string { return id }
+
+type MemoryStorage struct{}
+
+func (t *MemoryStorage) Load(id string) string { return id }
+
+type Service struct {
+	Storage Storage `inject`
+}
+
+type CachedService struct {
+	Cache Cache `inject`
+}
+
+type NamedService struct {
+	Storage Storage `inject:"bean=files"`
+}
+
+type TypoService struct {
+	Storage Storage `inject:"optinal"`
+}
+
+type TableService struct {
+	Storages map[string]Storage `inject`
+}
+
+type PrivateService struct {
+	storage Storage `inject`
+}
+
+type ClientFactory struct{}
+
+func (t *ClientFactory) Object() (*FileStorage, error) { return &FileStorage{}, nil }
+
+func (t *ClientFactory) ObjectName() string { return "" }
+
+func (t *ClientFactory) Singleton() bool { return true }
@@ -0,0 +1,22 @@
+module go.arpabet.com/beans/cmd
+
+// golang.org/x/tools v0.46.0 requires go 1.25, older versions can not read export data of current toolchains
+go 1.25.0
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.6.1
+	go.arpabet.com/beans v1.0.0
+	golang.org/x/tools v0.46.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.37.0 // indirect
+	golang.org/x/sync v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+// the tools use the working tree of the library until the root module has the tagged release with beans.Wired
+replace go.arpabet.com/beans => ../
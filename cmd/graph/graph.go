@@ -0,0 +1,256 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Package graph builds the wiring graph of contexts from the source code without running the program.
+
+Calls of beans.Create, beans.Wired and Context.Extend are followed with their arguments, lists of beans returned by functions
+and Scanner.Beans() implementations, and 'inject' fields are resolved the same way as the context does on creation.
+Missing and ambiguous dependencies and dependency cycles are errors. When the graph could not be fully known statically,
+for example the parent of Extend or the type produced by the factory, missing dependencies are warnings.
+*/
+package graph
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"strings"
+)
+
+/**
+Import path of the beans package
+*/
+const beansPath = "go.arpabet.com/beans"
+
+/**
+Wiring graph of all contexts found in the packages
+*/
+type Graph struct {
+	Contexts []*Context
+}
+
+/**
+Context created by the call in the source code
+*/
+type Context struct {
+
+	/**
+	Position of the call
+	*/
+	Pos string
+
+	/**
+	Called function, like beans.Create or ctx.Extend
+	*/
+	Call string
+
+	/**
+	Parent context for Extend, nil if the context is the root or the parent is unknown
+	*/
+	Parent *Context
+
+	/**
+	Scanned beans and objects produced by factories in scan order
+	*/
+	Beans []*Bean
+
+	/**
+	Problems found in the context
+	*/
+	Problems []*Problem
+
+	/**
+	Context itself, the bean that could be injected by beans.Context and beans.EventPublisher
+	*/
+	self *Bean
+
+	/**
+	Context has beans that are not known statically, missing dependencies could be provided by them
+	*/
+	incomplete bool
+}
+
+/**
+Bean of the context
+*/
+type Bean struct {
+
+	/**
+	Name of the bean used by qualifiers
+	*/
+	Name string
+
+	/**
+	Type of the bean, qualified by the package name like reflect does
+	*/
+	Type string
+
+	/**
+	Position of the scanned expression
+	*/
+	Pos string
+
+	/**
+	Factory producing the bean, nil for scanned beans
+	*/
+	Factory *Bean
+
+	/**
+	Injected fields of the bean
+	*/
+	Fields []*Field
+
+	typ         types.Type
+	unknownName bool
+	context     bool
+}
+
+/**
+Field of the bean with 'inject' tag
+*/
+type Field struct {
+
+	/**
+	Name of the field
+	*/
+	Name string
+
+	/**
+	Type of the field
+	*/
+	Type string
+
+	/**
+	Options of 'inject' tag
+	*/
+	Options string
+
+	/**
+	Resolved beans
+	*/
+	Targets []*Target
+
+	elem      types.Type
+	slice     bool
+	table     bool
+	optional  bool
+	lazy      bool
+	qualifier string
+	level     int
+}
+
+/**
+Bean injected in to the field
+*/
+type Target struct {
+	Bean *Bean
+
+	/**
+	Level of the context, 1 is the context of the field, 2 is the parent and so on
+	*/
+	Level int
+}
+
+/**
+Error or warning about the wiring
+*/
+type Problem struct {
+	Pos     string
+	Message string
+	Warning bool
+}
+
+func (t *Problem) String() string {
+	kind := "error"
+	if t.Warning {
+		kind = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s", t.Pos, kind, t.Message)
+}
+
+/**
+Returns all problems of all contexts
+*/
+func (t *Graph) Problems() []*Problem {
+	var list []*Problem
+	for _, ctx := range t.Contexts {
+		list = append(list, ctx.Problems...)
+	}
+	return list
+}
+
+/**
+Returns the number of errors and warnings
+*/
+func (t *Graph) Count() (errors int, warnings int) {
+	for _, p := range t.Problems() {
+		if p.Warning {
+			warnings++
+		} else {
+			errors++
+		}
+	}
+	return
+}
+
+/**
+Writes the graph as text, the output is stable for the same source code to be compared in reviews
+*/
+func (t *Graph) Write(w io.Writer) error {
+
+	var out strings.Builder
+	for _, ctx := range t.Contexts {
+		out.WriteString(ctx.Pos + " " + ctx.Call)
+		if ctx.Parent != nil {
+			out.WriteString(" parent " + ctx.Parent.Pos)
+		}
+		out.WriteString("\n")
+		for _, b := range ctx.Beans {
+			out.WriteString("  " + b.Type)
+			if b.Name != b.Type {
+				out.WriteString(fmt.Sprintf(" name '%s'", b.Name))
+			}
+			if b.Factory != nil {
+				out.WriteString(" factory " + b.Factory.Type)
+			}
+			out.WriteString("\n")
+			for _, f := range b.Fields {
+				out.WriteString("    " + f.Name + " " + f.Type)
+				if f.Options != "" {
+					out.WriteString(" [" + f.Options + "]")
+				}
+				out.WriteString(" -> ")
+				if len(f.Targets) == 0 {
+					out.WriteString("none")
+				} else {
+					var targets []string
+					for _, target := range f.Targets {
+						s := target.Bean.Type
+						if target.Bean.context {
+							s = "context"
+						} else if target.Bean.Name != target.Bean.Type {
+							s += fmt.Sprintf(" '%s'", target.Bean.Name)
+						}
+						if target.Level > 1 {
+							s += fmt.Sprintf(" level %d", target.Level)
+						}
+						targets = append(targets, s)
+					}
+					out.WriteString(strings.Join(targets, ", "))
+				}
+				out.WriteString("\n")
+			}
+		}
+		for _, p := range ctx.Problems {
+			out.WriteString("  " + p.String() + "\n")
+		}
+	}
+
+	errors, warnings := t.Count()
+	out.WriteString(fmt.Sprintf("%d contexts, %d errors, %d warnings\n", len(t.Contexts), errors, warnings))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
@@ -0,0 +1,92 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package graph_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans/cmd/graph"
+	"strings"
+	"testing"
+)
+
+const expectedGraph = `testdata/app/app.go:77 beans.Create
+  *app.fileStorage name 'files'
+  *app.memoryStorage
+  *app.service
+    Storage app.Storage [bean=files] -> *app.fileStorage 'files'
+    Client *app.client -> *app.client
+    Logger *app.logger -> *app.logger
+    All []app.Storage -> *app.fileStorage 'files', *app.memoryStorage
+    Ctx beans.Context -> context
+    Server app.Server -> *app.httpServer
+  *app.httpServer
+  *app.clientFactory
+  *app.client factory *app.clientFactory
+  beans.Factory[*app.logger]
+  *app.logger factory beans.Factory[*app.logger]
+testdata/app/app.go:86 ctx.Extend parent testdata/app/app.go:77
+  *app.handler
+    Storage app.Storage -> *app.fileStorage 'files' level 2, *app.memoryStorage level 2
+    Service *app.service -> *app.service level 2
+    Missing *app.missing -> none
+  testdata/app/app.go:86: error: field 'Storage' in *app.handler can not be injected with multiple candidates *app.fileStorage, *app.memoryStorage
+  testdata/app/app.go:86: error: can not find candidates to inject field 'Missing' in *app.handler
+testdata/app/app.go:91 beans.Create
+  *app.a
+    B *app.b -> *app.b
+  *app.b
+    A *app.a -> *app.a
+  testdata/app/app.go:91: error: detected cycle dependency *app.a->*app.b->*app.a
+3 contexts, 3 errors, 0 warnings
+`
+
+func TestGraph(t *testing.T) {
+
+	g, err := graph.Load("", false, "./testdata/app")
+	require.NoError(t, err)
+	require.Equal(t, 3, len(g.Contexts))
+
+	root, child := g.Contexts[0], g.Contexts[1]
+	require.True(t, child.Parent == root)
+	require.Equal(t, 0, len(root.Problems))
+
+	errors, warnings := g.Count()
+	require.Equal(t, 3, errors)
+	require.Equal(t, 0, warnings)
+
+	var out strings.Builder
+	require.NoError(t, g.Write(&out))
+	require.Equal(t, expectedGraph, out.String())
+}
+
+func TestGraphGenerated(t *testing.T) {
+
+	g, err := graph.Load("", false, "../gen/internal/example")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(g.Contexts))
+	require.Equal(t, "beans.Wired", g.Contexts[0].Call)
+	require.Equal(t, 4, len(g.Contexts[0].Beans))
+	require.Equal(t, 0, len(g.Problems()))
+}
+
+func TestGraphWarnings(t *testing.T) {
+
+	g, err := graph.Load("", false, "./testdata/partial")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(g.Contexts))
+	require.Nil(t, g.Contexts[0].Parent)
+
+	errors, warnings := g.Count()
+	require.Equal(t, 0, errors)
+	require.Equal(t, 4, warnings)
+
+	var messages []string
+	for _, p := range g.Problems() {
+		messages = append(messages, p.Message)
+	}
+	require.Contains(t, messages, "parent context 'ctx' is not known statically")
+	require.Contains(t, messages, "object type of factory '*partial.clientFactory' is not known statically")
+	require.Contains(t, messages, "can not find candidates to inject field 'Client' in *partial.consumer")
+}
@@ -0,0 +1,688 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/**
+Maximum depth of following functions returning lists of beans
+*/
+const maxDepth = 8
+
+/**
+Source of the declaration with type information of its package
+*/
+type source struct {
+	node ast.Node
+	info *types.Info
+}
+
+/**
+Context call waiting for resolution of the parent
+*/
+type extendCall struct {
+	ctx  *Context
+	recv ast.Expr
+	info *types.Info
+}
+
+type loader struct {
+	fset *token.FileSet
+	dir  string
+
+	/**
+	Declarations of functions and methods in loaded packages
+	*/
+	funcs map[*types.Func]source
+
+	/**
+	Initializers of package variables in loaded packages
+	*/
+	vars map[*types.Var]source
+
+	/**
+	Variables holding contexts
+	*/
+	holders map[types.Object]*Context
+
+	extends []extendCall
+	graph   *Graph
+}
+
+/**
+Loads packages matching patterns in the directory and builds the graph of contexts created in them, including test files if needed
+*/
+func Load(dir string, tests bool, patterns ...string) (*Graph, error) {
+
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:   dir,
+		Tests: tests,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, errors.Errorf("no packages match %v", patterns)
+	}
+	if tests {
+		pkgs = withoutTestDuplicates(pkgs)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, errors.Errorf("package '%s' has errors, %v", pkg.PkgPath, pkg.Errors[0])
+		}
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &loader{
+		fset:    pkgs[0].Fset,
+		dir:     abs,
+		funcs:   make(map[*types.Func]source),
+		vars:    make(map[*types.Var]source),
+		holders: make(map[types.Object]*Context),
+		graph:   &Graph{},
+	}
+
+	for _, pkg := range pkgs {
+		t.declarations(pkg)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			t.contexts(pkg.TypesInfo, file)
+		}
+	}
+	for _, call := range t.extends {
+		if ident, ok := ast.Unparen(call.recv).(*ast.Ident); ok {
+			call.ctx.Parent = t.holders[call.info.Uses[ident]]
+		}
+		if call.ctx.Parent == nil {
+			call.ctx.incomplete = true
+			call.ctx.Problems = append(call.ctx.Problems, &Problem{
+				Pos:     call.ctx.Pos,
+				Message: fmt.Sprintf("parent context '%s' is not known statically", types.ExprString(call.recv)),
+				Warning: true,
+			})
+		}
+	}
+	for _, ctx := range t.graph.Contexts {
+		ctx.resolve()
+	}
+	return t.graph, nil
+}
+
+/**
+Removes packages that are included in their test variants and generated test mains
+*/
+func withoutTestDuplicates(pkgs []*packages.Package) []*packages.Package {
+	variants := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.ID != pkg.PkgPath && !strings.HasSuffix(pkg.PkgPath, "_test") {
+			variants[pkg.PkgPath] = true
+		}
+	}
+	var list []*packages.Package
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test") || (pkg.ID == pkg.PkgPath && variants[pkg.PkgPath]) {
+			continue
+		}
+		list = append(list, pkg)
+	}
+	return list
+}
+
+/**
+Collects declarations of functions and initializers of package variables
+*/
+func (t *loader) declarations(pkg *packages.Package) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if fn, ok := pkg.TypesInfo.Defs[d.Name].(*types.Func); ok && d.Body != nil {
+					t.funcs[fn] = source{node: d, info: pkg.TypesInfo}
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					vs, ok := spec.(*ast.ValueSpec)
+					if !ok || len(vs.Values) != len(vs.Names) {
+						continue
+					}
+					for i, name := range vs.Names {
+						if v, ok := pkg.TypesInfo.Defs[name].(*types.Var); ok {
+							t.vars[v] = source{node: vs.Values[i], info: pkg.TypesInfo}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+/**
+Finds calls creating contexts in the file in source order
+*/
+func (t *loader) contexts(info *types.Info, file *ast.File) {
+
+	handled := make(map[*ast.CallExpr]bool)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Rhs) == 1 && len(node.Lhs) > 0 {
+				if ctx := t.contextCall(info, node.Rhs[0], handled); ctx != nil {
+					if ident, ok := node.Lhs[0].(*ast.Ident); ok {
+						t.hold(info, ident, ctx)
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if len(node.Values) == 1 && len(node.Names) > 0 {
+				if ctx := t.contextCall(info, node.Values[0], handled); ctx != nil {
+					t.hold(info, node.Names[0], ctx)
+				}
+			}
+		case *ast.CallExpr:
+			t.contextCall(info, node, handled)
+		}
+		return true
+	})
+}
+
+func (t *loader) hold(info *types.Info, ident *ast.Ident, ctx *Context) {
+	if obj := info.Defs[ident]; obj != nil {
+		t.holders[obj] = ctx
+	} else if obj := info.Uses[ident]; obj != nil {
+		t.holders[obj] = ctx
+	}
+}
+
+/**
+Creates the context if the expression is the call of beans.Create, beans.Wired or Context.Extend
+*/
+func (t *loader) contextCall(info *types.Info, expr ast.Expr, handled map[*ast.CallExpr]bool) *Context {
+
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || handled[call] {
+		return nil
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != beansPath {
+		return nil
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	var extend bool
+	switch {
+	case recv == nil && (fn.Name() == "Create" || fn.Name() == "Wired"):
+	case recv != nil && fn.Name() == "Extend":
+		extend = true
+	default:
+		return nil
+	}
+	handled[call] = true
+
+	ctx := &Context{
+		Pos:  t.position(call.Pos()),
+		Call: types.ExprString(call.Fun),
+	}
+	if obj, ok := fn.Pkg().Scope().Lookup("Context").(*types.TypeName); ok {
+		ctx.self = &Bean{Type: typeString(obj.Type()), Pos: ctx.Pos, typ: obj.Type(), context: true}
+	}
+	t.graph.Contexts = append(t.graph.Contexts, ctx)
+	if extend {
+		t.extends = append(t.extends, extendCall{ctx: ctx, recv: sel.X, info: info})
+	}
+
+	for i, arg := range call.Args {
+		if call.Ellipsis.IsValid() && i == len(call.Args)-1 {
+			t.scanList(ctx, info, arg, 0)
+		} else {
+			t.scanExpr(ctx, info, arg, 0)
+		}
+	}
+	return ctx
+}
+
+/**
+Scans the expression of type []interface{}
+*/
+func (t *loader) scanList(ctx *Context, info *types.Info, expr ast.Expr, depth int) {
+
+	if depth > maxDepth {
+		t.unknown(ctx, expr, "list of beans is too deep")
+		return
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CompositeLit:
+		for _, el := range e.Elts {
+			t.scanExpr(ctx, info, el, depth)
+		}
+		return
+	case *ast.Ident:
+		if e.Name == "nil" {
+			return
+		}
+		if v, ok := info.Uses[e].(*types.Var); ok {
+			if src, ok := t.vars[v]; ok {
+				t.scanList(ctx, src.info, src.node.(ast.Expr), depth+1)
+				return
+			}
+		}
+	case *ast.CallExpr:
+		if fn := calledFunc(info, e); fn != nil {
+			if src, ok := t.funcs[fn]; ok {
+				t.scanResults(ctx, src, depth+1)
+				return
+			}
+		}
+	}
+
+	t.unknown(ctx, expr, "list of beans is not known statically")
+}
+
+/**
+Scans expressions returned by the function as lists of beans
+*/
+func (t *loader) scanResults(ctx *Context, src source, depth int) {
+	decl := src.node.(*ast.FuncDecl)
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(node.Results) == 1 {
+				t.scanList(ctx, src.info, node.Results[0], depth)
+			}
+		}
+		return true
+	})
+}
+
+/**
+Scans the single argument of the context call or the element of the list
+*/
+func (t *loader) scanExpr(ctx *Context, info *types.Info, expr ast.Expr, depth int) {
+
+	tv, ok := info.Types[expr]
+	if !ok || tv.IsNil() {
+		return
+	}
+	typ := tv.Type
+
+	if isInterfaceList(typ) {
+		t.scanList(ctx, info, expr, depth)
+		return
+	}
+
+	if fn := methodOf(typ, "Beans"); fn != nil && isScanner(fn) {
+		if src, ok := t.funcs[fn]; ok {
+			t.scanResults(ctx, src, depth+1)
+		} else {
+			t.unknown(ctx, expr, fmt.Sprintf("beans of scanner '%s' are not known statically", typeString(typ)))
+		}
+		return
+	}
+
+	if iface, ok := typ.Underlying().(*types.Interface); ok && iface.Empty() {
+		t.unknown(ctx, expr, fmt.Sprintf("type of '%s' is not known statically", types.ExprString(expr)))
+		return
+	}
+
+	t.addBean(ctx, typ, t.position(expr.Pos()))
+}
+
+/**
+Marks the context incomplete with the warning
+*/
+func (t *loader) unknown(ctx *Context, expr ast.Expr, message string) {
+	ctx.incomplete = true
+	ctx.Problems = append(ctx.Problems, &Problem{
+		Pos:     t.position(expr.Pos()),
+		Message: message,
+		Warning: true,
+	})
+}
+
+/**
+Adds the scanned bean and objects of the factory
+*/
+func (t *loader) addBean(ctx *Context, typ types.Type, pos string) {
+
+	b := &Bean{
+		Type: typeString(typ),
+		Pos:  pos,
+		typ:  typ,
+	}
+	b.Name = b.Type
+	if fn := methodOf(typ, "BeanName"); fn != nil {
+		if name, ok := t.constString(fn); ok {
+			b.Name = name
+		} else {
+			b.unknownName = true
+		}
+	}
+	ctx.Beans = append(ctx.Beans, b)
+
+	if st, ok := structOf(typ); ok {
+		for i := 0; i < st.NumFields(); i++ {
+			if f := injectField(st.Field(i), reflect.StructTag(st.Tag(i))); f != nil {
+				b.Fields = append(b.Fields, f)
+			}
+		}
+	}
+
+	if elem, ok := factoryTypeArg(typ); ok {
+		t.addProduct(ctx, b, elem, nil)
+		return
+	}
+
+	object := methodOf(typ, "Object")
+	if object == nil {
+		if methodOf(typ, "Objects") != nil {
+			t.incomplete(ctx, b, "objects of multi factory '%s' are not known statically")
+		}
+		return
+	}
+	objectName := methodOf(typ, "ObjectName")
+	if objectName == nil || methodOf(typ, "Singleton") == nil {
+		return
+	}
+
+	if objectType := methodOf(typ, "ObjectType"); objectType != nil {
+		if elem, ok := t.reflectType(objectType); ok {
+			t.addProduct(ctx, b, elem, objectName)
+		} else {
+			t.incomplete(ctx, b, "object type of factory '%s' is not known statically")
+		}
+		return
+	}
+
+	sig := object.Type().(*types.Signature)
+	if sig.Results().Len() == 2 {
+		t.addProduct(ctx, b, sig.Results().At(0).Type(), objectName)
+	}
+}
+
+func (t *loader) incomplete(ctx *Context, b *Bean, format string) {
+	ctx.incomplete = true
+	ctx.Problems = append(ctx.Problems, &Problem{
+		Pos:     b.Pos,
+		Message: fmt.Sprintf(format, b.Type),
+		Warning: true,
+	})
+}
+
+/**
+Adds the object produced by the factory, the name is the result of ObjectName or the type
+*/
+func (t *loader) addProduct(ctx *Context, factory *Bean, typ types.Type, objectName *types.Func) {
+	b := &Bean{
+		Type:    typeString(typ),
+		Pos:     factory.Pos,
+		Factory: factory,
+		typ:     typ,
+	}
+	b.Name = b.Type
+	if objectName != nil {
+		if name, ok := t.constString(objectName); !ok {
+			b.unknownName = true
+		} else if name != "" {
+			b.Name = name
+		}
+	}
+	ctx.Beans = append(ctx.Beans, b)
+}
+
+/**
+Returns the constant string returned by the method without arguments
+*/
+func (t *loader) constString(fn *types.Func) (string, bool) {
+	results := t.returns(fn)
+	if len(results) != 1 {
+		return "", false
+	}
+	tv := results[0].info.Types[results[0].node.(ast.Expr)]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+/**
+Evaluates the type returned by ObjectType method, supports reflect.TypeOf with optional Elem call directly or by the package variable
+*/
+func (t *loader) reflectType(fn *types.Func) (types.Type, bool) {
+	results := t.returns(fn)
+	if len(results) != 1 {
+		return nil, false
+	}
+	return t.evalType(results[0].info, results[0].node.(ast.Expr), 0)
+}
+
+func (t *loader) evalType(info *types.Info, expr ast.Expr, depth int) (types.Type, bool) {
+
+	if depth > maxDepth {
+		return nil, false
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if v, ok := info.Uses[e].(*types.Var); ok {
+			if src, ok := t.vars[v]; ok {
+				return t.evalType(src.info, src.node.(ast.Expr), depth+1)
+			}
+		}
+	case *ast.CallExpr:
+		sel, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return nil, false
+		}
+		if fn, ok := info.Uses[sel.Sel].(*types.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == "reflect" && fn.Name() == "TypeOf" && len(e.Args) == 1 {
+			typ := info.TypeOf(e.Args[0])
+			return typ, typ != nil
+		}
+		if sel.Sel.Name == "Elem" && len(e.Args) == 0 {
+			typ, ok := t.evalType(info, sel.X, depth+1)
+			if !ok {
+				return nil, false
+			}
+			if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+				return ptr.Elem(), true
+			}
+		}
+	}
+	return nil, false
+}
+
+/**
+Returns expressions of return statements of the function declared in loaded packages
+*/
+func (t *loader) returns(fn *types.Func) []source {
+	src, ok := t.funcs[fn]
+	if !ok {
+		return nil
+	}
+	var list []source
+	ast.Inspect(src.node.(*ast.FuncDecl).Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(node.Results) == 1 {
+				list = append(list, source{node: node.Results[0], info: src.info})
+			}
+		}
+		return true
+	})
+	return list
+}
+
+func (t *loader) position(pos token.Pos) string {
+	p := t.fset.Position(pos)
+	name := p.Filename
+	if rel, err := filepath.Rel(t.dir, name); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(name), p.Line)
+}
+
+/**
+Parses the field with 'inject' tag, returns nil for other fields
+*/
+func injectField(v *types.Var, tag reflect.StructTag) *Field {
+
+	injectTag, hasInjectTag := tag.Lookup("inject")
+	if tag != "inject" && !hasInjectTag {
+		return nil
+	}
+
+	f := &Field{
+		Name:    v.Name(),
+		Type:    typeString(v.Type()),
+		Options: injectTag,
+		elem:    v.Type(),
+	}
+
+	for _, pair := range strings.Split(injectTag, ",") {
+		kv := strings.Split(strings.TrimSpace(pair), "=")
+		switch strings.TrimSpace(kv[0]) {
+		case "bean":
+			if len(kv) > 1 {
+				f.qualifier = strings.TrimSpace(kv[1])
+			}
+		case "optional":
+			f.optional = true
+		case "lazy":
+			f.lazy = true
+		case "level":
+			if len(kv) > 1 {
+				f.level, _ = strconv.Atoi(kv[1])
+			}
+		}
+	}
+
+	switch typ := v.Type().Underlying().(type) {
+	case *types.Slice:
+		f.slice = true
+		f.elem = typ.Elem()
+	case *types.Map:
+		f.table = true
+		f.elem = typ.Elem()
+	}
+	return f
+}
+
+/**
+Returns the function or method called by the expression
+*/
+func calledFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		fn, _ := info.Uses[fun].(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		fn, _ := info.Uses[fun.Sel].(*types.Func)
+		return fn
+	}
+	return nil
+}
+
+/**
+Returns the method of the type or nil
+*/
+func methodOf(typ types.Type, name string) *types.Func {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+/**
+Checks the signature of Scanner.Beans method
+*/
+func isScanner(fn *types.Func) bool {
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 && isInterfaceList(sig.Results().At(0).Type())
+}
+
+/**
+Checks if the type is []interface{}
+*/
+func isInterfaceList(typ types.Type) bool {
+	slice, ok := typ.(*types.Slice)
+	if !ok {
+		return false
+	}
+	iface, ok := slice.Elem().Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+/**
+Returns the struct of the pointer to struct type
+*/
+func structOf(typ types.Type) (*types.Struct, bool) {
+	ptr, ok := typ.(*types.Pointer)
+	if !ok {
+		return nil, false
+	}
+	st, ok := ptr.Elem().Underlying().(*types.Struct)
+	return st, ok
+}
+
+/**
+Returns T of beans.Factory[T] interface type
+*/
+func factoryTypeArg(typ types.Type) (types.Type, bool) {
+	named, ok := typ.(*types.Named)
+	if !ok || named.TypeArgs().Len() != 1 || !isBeansType(named.Origin(), "Factory") {
+		return nil, false
+	}
+	return named.TypeArgs().At(0), true
+}
+
+/**
+Checks if the type is the named type of beans package
+*/
+func isBeansType(typ types.Type, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == beansPath && obj.Name() == name
+}
+
+/**
+Returns the type qualified by the package name, the same as reflect.Type.String()
+*/
+func typeString(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
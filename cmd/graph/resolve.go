@@ -0,0 +1,235 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+/**
+Resolves injected fields of beans and finds dependency cycles
+*/
+func (t *Context) resolve() {
+	for _, b := range t.Beans {
+		for _, f := range b.Fields {
+			t.resolveField(b, f)
+		}
+	}
+	t.findCycles()
+}
+
+/**
+Context and parents are not fully known, so missing beans could exist
+*/
+func (t *Context) uncertain() bool {
+	for ctx := t; ctx != nil; ctx = ctx.Parent {
+		if ctx.incomplete {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+Finds candidates of the field like injection does: by the level, then by the qualifier
+*/
+func (t *Context) resolveField(b *Bean, f *Field) {
+
+	var deep [][]*Target
+	level := 1
+	for ctx := t; ctx != nil; ctx = ctx.Parent {
+		var list []*Target
+		if ctx.self != nil && ctx.self.matchesContext(f.elem) {
+			list = append(list, &Target{Bean: ctx.self, Level: level})
+		}
+		for _, candidate := range ctx.Beans {
+			if candidate.matches(f.elem) {
+				list = append(list, &Target{Bean: candidate, Level: level})
+			}
+		}
+		if len(list) > 0 {
+			deep = append(deep, list)
+		}
+		level++
+	}
+
+	var candidates []*Target
+	uncertainName := false
+	for _, target := range levelTargets(deep, f.level) {
+		if target.Bean.unknownName {
+			uncertainName = true
+		}
+		if f.qualifier == "" || f.qualifier == target.Bean.Name {
+			candidates = append(candidates, target)
+		}
+	}
+
+	describe := fmt.Sprintf("field '%s' in %s", f.Name, b.Type)
+	if f.qualifier != "" {
+		describe += fmt.Sprintf(" with qualifier '%s'", f.qualifier)
+	}
+
+	if len(candidates) == 0 {
+		if !f.optional {
+			t.problem(b, t.uncertain() || uncertainName, "can not find candidates to inject %s", describe)
+		}
+		return
+	}
+
+	if f.table {
+		visited := make(map[string]bool)
+		for _, target := range candidates {
+			if visited[target.Bean.Name] && !target.Bean.unknownName {
+				t.problem(b, false, "can not inject duplicates '%s' to the map %s", target.Bean.Name, describe)
+			}
+			visited[target.Bean.Name] = true
+		}
+	} else if !f.slice && len(candidates) > 1 {
+		var names []string
+		for _, target := range candidates {
+			names = append(names, target.Bean.Type)
+		}
+		t.problem(b, false, "%s can not be injected with multiple candidates %s", describe, strings.Join(names, ", "))
+	}
+
+	f.Targets = candidates
+}
+
+/**
+Selects targets for the level of injection, see injectionDef.level
+*/
+func levelTargets(deep [][]*Target, level int) []*Target {
+	if len(deep) == 0 {
+		return nil
+	}
+	switch level {
+	case -1:
+		var list []*Target
+		for _, entry := range deep {
+			list = append(list, entry...)
+		}
+		return list
+	case 0:
+		return deep[0]
+	case 1:
+		if deep[0][0].Level == 1 {
+			return deep[0]
+		}
+		return nil
+	default:
+		var list []*Target
+		for _, entry := range deep {
+			if entry[0].Level > level {
+				break
+			}
+			list = append(list, entry...)
+		}
+		return list
+	}
+}
+
+/**
+Checks if the bean could be injected in to the field of the type, interface beans are matched by their methods
+*/
+func (t *Bean) matches(fieldType types.Type) bool {
+	switch typ := fieldType.Underlying().(type) {
+	case *types.Interface:
+		return types.Implements(t.typ, typ)
+	default:
+		return types.Identical(t.typ, fieldType)
+	}
+}
+
+/**
+Context is the candidate only of beans.Context and beans.EventPublisher like on injection,
+other interfaces matched by its methods belong to beans
+*/
+func (t *Bean) matchesContext(fieldType types.Type) bool {
+	named, ok := fieldType.(*types.Named)
+	self, isNamed := t.typ.(*types.Named)
+	if !ok || !isNamed || named.Obj().Pkg() != self.Obj().Pkg() {
+		return false
+	}
+	switch named.Obj().Name() {
+	case "Context", "EventPublisher":
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *Context) problem(b *Bean, warning bool, format string, args ...interface{}) {
+	t.Problems = append(t.Problems, &Problem{
+		Pos:     b.Pos,
+		Message: fmt.Sprintf(format, args...),
+		Warning: warning,
+	})
+}
+
+/**
+Reports cycles of non-lazy dependencies between beans of the context, the context fails to construct them
+*/
+func (t *Context) findCycles() {
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := make(map[*Bean]int)
+	var stack []*Bean
+	var visit func(b *Bean)
+	visit = func(b *Bean) {
+		state[b] = visiting
+		stack = append(stack, b)
+		for _, dep := range t.dependencies(b) {
+			switch state[dep] {
+			case visiting:
+				var path []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					path = append([]string{stack[i].Type}, path...)
+					if stack[i] == dep {
+						break
+					}
+				}
+				path = append(path, dep.Type)
+				t.problem(dep, false, "detected cycle dependency %s", strings.Join(path, "->"))
+			case 0:
+				visit(dep)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[b] = visited
+	}
+
+	for _, b := range t.Beans {
+		if state[b] == 0 {
+			visit(b)
+		}
+	}
+}
+
+/**
+Returns beans of the same context that must be constructed before the bean
+*/
+func (t *Context) dependencies(b *Bean) []*Bean {
+	var list []*Bean
+	if b.Factory != nil {
+		list = append(list, b.Factory)
+	}
+	for _, f := range b.Fields {
+		if f.lazy {
+			continue
+		}
+		for _, target := range f.Targets {
+			if target.Level == 1 && target.Bean != b {
+				list = append(list, target.Bean)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"go.arpabet.com/beans"
+	"reflect"
+)
+
+type Storage interface {
+	Load(id string) string
+}
+
+type fileStorage struct{}
+
+func (t *fileStorage) Load(id string) string { return id }
+
+func (t *fileStorage) BeanName() string { return "files" }
+
+type memoryStorage struct{}
+
+func (t *memoryStorage) Load(id string) string { return id }
+
+type client struct{}
+
+var clientClass = reflect.TypeOf((*client)(nil))
+
+type clientFactory struct{}
+
+func (t *clientFactory) Object() (interface{}, error) { return &client{}, nil }
+
+func (t *clientFactory) ObjectType() reflect.Type { return clientClass }
+
+func (t *clientFactory) ObjectName() string { return "" }
+
+func (t *clientFactory) Singleton() bool { return true }
+
+type logger struct{}
+
+type service struct {
+	Storage Storage       `inject:"bean=files"`
+	Client  *client       `inject`
+	Logger  *logger       `inject`
+	All     []Storage     `inject`
+	Ctx     beans.Context `inject`
+	Server  Server        `inject`
+}
+
+type storages struct{}
+
+func (t *storages) Beans() []interface{} {
+	return []interface{}{&fileStorage{}, &memoryStorage{}}
+}
+
+type handler struct {
+	Storage Storage  `inject`
+	Service *service `inject`
+	Missing *missing `inject`
+}
+
+type missing struct{}
+
+type a struct {
+	B *b `inject`
+}
+
+type b struct {
+	A *a `inject`
+}
+
+func common() []interface{} {
+	return []interface{}{
+		&clientFactory{},
+		beans.FactoryFunc(func() (*logger, error) { return &logger{}, nil }),
+	}
+}
+
+func Run() error {
+	ctx, err := beans.Create(
+		&storages{},
+		&service{},
+		&httpServer{},
+		common(),
+	)
+	if err != nil {
+		return err
+	}
+	child, err := ctx.Extend(&handler{})
+	if err != nil {
+		return err
+	}
+	child.Close()
+	_, err = beans.Create(&a{}, &b{})
+	return err
+}
+
+// the context has methods Start and Stop too, but it is not the candidate of Server
+type Server interface {
+	Start() error
+	Stop() error
+}
+
+type httpServer struct{}
+
+func (t *httpServer) Start() error { return nil }
+
+func (t *httpServer) Stop() error { return nil }
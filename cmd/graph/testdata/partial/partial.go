@@ -0,0 +1,32 @@
+package partial
+
+import (
+	"go.arpabet.com/beans"
+	"reflect"
+)
+
+type client struct{}
+
+type clientFactory struct{}
+
+func (t *clientFactory) Object() (interface{}, error) { return &client{}, nil }
+
+func (t *clientFactory) ObjectType() reflect.Type { return typeOf() }
+
+func (t *clientFactory) ObjectName() string { return "" }
+
+func (t *clientFactory) Singleton() bool { return true }
+
+func typeOf() reflect.Type { return reflect.TypeOf((*client)(nil)) }
+
+type consumer struct {
+	Client *client `inject`
+}
+
+func Extend(ctx beans.Context, scan []interface{}) (beans.Context, error) {
+	return ctx.Extend(append(scan, &consumer{})...)
+}
+
+func Create() (beans.Context, error) {
+	return beans.Create(&clientFactory{}, &consumer{})
+}
@@ -0,0 +1,244 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Package lint provides the analyzer of beans structs that reports on compile time what the context reports only on creation.
+
+The analyzer checks 'inject' tags on unexported and anonymous fields, on fields of unsupported types, unknown tag options,
+types embedding beans.Context and types embedding lifecycle interfaces without implementing their methods, since the context replaces them by stubs.
+
+Example:
+	go vet -vettool=$(which beanslint) ./...
+*/
+package lint
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/**
+Import path of the beans package
+*/
+const beansPath = "go.arpabet.com/beans"
+
+/**
+Analyzer of beans structs for go vet and multichecker
+*/
+var Analyzer = &analysis.Analyzer{
+	Name:     "beans",
+	Doc:      "check inject tags and embedded interfaces of beans",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+/**
+Interfaces replaced by stubs in anonymous fields of beans, the stub returns error or default value instead of the method of the bean
+*/
+var stubbedInterfaces = map[string]bool{
+	"InitializingBean": true,
+	"DisposableBean":   true,
+	"FactoryBean":      true,
+	"NamedBean":        true,
+	"OrderedBean":      true,
+}
+
+/**
+Options of 'inject' tag, true if the option has the value
+*/
+var injectOptions = map[string]bool{
+	"bean":     true,
+	"optional": false,
+	"lazy":     false,
+	"dynamic":  false,
+	"level":    true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil), (*ast.TypeSpec)(nil)}, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.StructType:
+			checkStruct(pass, node)
+		case *ast.TypeSpec:
+			if _, ok := node.Type.(*ast.StructType); ok {
+				checkEmbedded(pass, node)
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+/**
+Checks fields with 'inject' tag and embedded beans.Context
+*/
+func checkStruct(pass *analysis.Pass, node *ast.StructType) {
+
+	for _, field := range node.Fields.List {
+
+		typ := pass.TypesInfo.TypeOf(field.Type)
+		if typ == nil {
+			continue
+		}
+
+		if len(field.Names) == 0 && isBeansType(typ, "Context") {
+			pass.Reportf(field.Pos(), "embedding beans.Context is not allowed")
+		}
+
+		if field.Tag == nil {
+			continue
+		}
+		tagValue, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		tag := reflect.StructTag(tagValue)
+		injectTag, hasInjectTag := tag.Lookup("inject")
+		if tag != "inject" && !hasInjectTag {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			pass.Reportf(field.Pos(), "inject tag on anonymous field is not allowed")
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				pass.Reportf(name.Pos(), "inject tag on unexported field '%s', the field must be public", name.Name)
+			}
+		}
+
+		collection := checkType(pass, field, typ)
+
+		if hasInjectTag {
+			checkOptions(pass, field, injectTag, collection)
+		}
+	}
+}
+
+/**
+Checks that the type of the field could be injected, returns true for slices and maps
+*/
+func checkType(pass *analysis.Pass, field *ast.Field, typ types.Type) bool {
+
+	elem := typ
+	var collection bool
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		collection = true
+		elem = t.Elem()
+	case *types.Map:
+		collection = true
+		elem = t.Elem()
+		if key, ok := t.Key().Underlying().(*types.Basic); !ok || key.Kind() != types.String {
+			pass.Reportf(field.Type.Pos(), "map with key '%s' can not be injected, the key must be string", t.Key())
+			return collection
+		}
+	}
+
+	switch elem.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Signature:
+	default:
+		pass.Reportf(field.Type.Pos(), "type '%s' can not be injected, expected pointer, interface or function", elem)
+	}
+	return collection
+}
+
+/**
+Checks options of 'inject' tag
+*/
+func checkOptions(pass *analysis.Pass, field *ast.Field, injectTag string, collection bool) {
+
+	for _, pair := range strings.Split(injectTag, ",") {
+		kv := strings.Split(strings.TrimSpace(pair), "=")
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			continue
+		}
+		hasValue, known := injectOptions[key]
+		if !known {
+			pass.Reportf(field.Tag.Pos(), "unknown inject option '%s'", key)
+			continue
+		}
+		if hasValue && len(kv) < 2 {
+			pass.Reportf(field.Tag.Pos(), "inject option '%s' requires value", key)
+			continue
+		}
+		switch key {
+		case "level":
+			if _, err := strconv.Atoi(strings.TrimSpace(kv[1])); err != nil {
+				pass.Reportf(field.Tag.Pos(), "inject option 'level' must be integer, but was '%s'", kv[1])
+			}
+		case "dynamic":
+			if !collection {
+				pass.Reportf(field.Tag.Pos(), "inject option 'dynamic' is supported only for slice or map")
+			}
+		}
+	}
+}
+
+/**
+Checks that the named struct implements methods of embedded interfaces replaced by stubs
+*/
+func checkEmbedded(pass *analysis.Pass, spec *ast.TypeSpec) {
+
+	obj, ok := pass.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+	ptr := types.NewPointer(obj.Type())
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Embedded() {
+			continue
+		}
+		named, ok := field.Type().(*types.Named)
+		if !ok || !isBeansType(named, named.Obj().Name()) || !stubbedInterfaces[named.Obj().Name()] {
+			continue
+		}
+		iface, ok := named.Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		var missing []string
+		for j := 0; j < iface.NumMethods(); j++ {
+			m := iface.Method(j)
+			_, index, _ := types.LookupFieldOrMethod(ptr, true, m.Pkg(), m.Name())
+			if len(index) > 1 && index[0] == i {
+				// method is promoted from the embedded interface, not declared by the struct or other embedded types
+				missing = append(missing, m.Name())
+			}
+		}
+		if len(missing) > 0 {
+			pass.Reportf(field.Pos(), "%s embeds beans.%s without implementing %s", obj.Name(), named.Obj().Name(), strings.Join(missing, ", "))
+		}
+	}
+}
+
+/**
+Checks if the type is the named type of beans package
+*/
+func isBeansType(typ types.Type, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == beansPath && obj.Name() == name
+}
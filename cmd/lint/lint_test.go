@@ -0,0 +1,15 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package lint_test
+
+import (
+	"go.arpabet.com/beans/cmd/lint"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), lint.Analyzer, "a")
+}
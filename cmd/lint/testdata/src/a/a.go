@@ -0,0 +1,59 @@
+package a
+
+import "go.arpabet.com/beans"
+
+type Storage interface {
+	Load() string
+}
+
+type storageImpl struct{}
+
+type valid struct {
+	Storage  Storage             `inject`
+	Impl     *storageImpl        `inject:"optional,level=1"`
+	List     []Storage           `inject:"dynamic"`
+	Map      map[string]Storage  `inject:"bean=main"`
+	Provider func() *storageImpl `inject:"lazy"`
+	Ctx      beans.Context       `inject`
+	name     string
+}
+
+type unexported struct {
+	storage Storage `inject` // want `inject tag on unexported field 'storage', the field must be public`
+}
+
+type anonymous struct {
+	Storage `inject` // want `inject tag on anonymous field is not allowed`
+}
+
+type kinds struct {
+	Value   storageImpl             `inject` // want `type 'a.storageImpl' can not be injected, expected pointer, interface or function`
+	Numbers map[int]Storage         `inject` // want `map with key 'int' can not be injected, the key must be string`
+	Values  []storageImpl           `inject` // want `type 'a.storageImpl' can not be injected, expected pointer, interface or function`
+	Names   map[string]*storageImpl `inject`
+}
+
+type options struct {
+	A Storage   `inject:"optinal"` // want `unknown inject option 'optinal'`
+	B Storage   `inject:"level=x"` // want `inject option 'level' must be integer, but was 'x'`
+	C Storage   `inject:"bean"`    // want `inject option 'bean' requires value`
+	D Storage   `inject:"dynamic"` // want `inject option 'dynamic' is supported only for slice or map`
+	E []Storage `inject:"optional"`
+}
+
+type exposed struct {
+	beans.Context // want `embedding beans.Context is not allowed`
+}
+
+type lifecycle struct {
+	beans.InitializingBean // want `lifecycle embeds beans.InitializingBean without implementing PostConstruct`
+	beans.DisposableBean
+}
+
+func (t *lifecycle) Destroy() error {
+	return nil
+}
+
+type named struct {
+	beans.NamedBean // want `named embeds beans.NamedBean without implementing BeanName`
+}
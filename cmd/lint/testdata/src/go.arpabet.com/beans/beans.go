@@ -0,0 +1,17 @@
+package beans
+
+type Context interface {
+	Close() error
+}
+
+type InitializingBean interface {
+	PostConstruct() error
+}
+
+type DisposableBean interface {
+	Destroy() error
+}
+
+type NamedBean interface {
+	BeanName() string
+}
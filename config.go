@@ -0,0 +1,344 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type configDef struct {
+
+	/**
+	Key prefix of all properties of the config struct, could be empty
+	*/
+	prefix string
+}
+
+/**
+Parses 'config' tag of the blank field in format 'prefix=name'
+*/
+func parseConfigDef(field reflect.StructField, j int, tag string, classPtr reflect.Type) (*configDef, error) {
+	if classPtr.Kind() != reflect.Ptr || classPtr.Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("config tag is supported only by struct, but type is '%v'", classPtr)
+	}
+	def := &configDef{}
+	for _, pair := range strings.Split(tag, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		switch strings.TrimSpace(kv[0]) {
+		case "prefix":
+			if len(kv) > 1 {
+				def.prefix = strings.TrimSpace(kv[1])
+			}
+		case "":
+		default:
+			return nil, errors.Errorf("unknown option '%s' in 'config' tag of field '%s' on position %d in %v", kv[0], field.Name, j, classPtr)
+		}
+	}
+	if err := checkConfig(classPtr.Elem(), make(map[reflect.Type]bool)); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+/**
+Checks 'config' tags of the struct and nested structs, so the typo in options fails the scan
+*/
+func checkConfig(typ reflect.Type, visited map[reflect.Type]bool) error {
+	if visited[typ] {
+		return nil
+	}
+	visited[typ] = true
+	for j := 0; j < typ.NumField(); j++ {
+		field := typ.Field(j)
+		tag, ok := field.Tag.Lookup("config")
+		if !ok || field.Name == "_" {
+			continue
+		}
+		if _, err := parseConfigOption(field, j, tag, typ); err != nil {
+			return err
+		}
+		if nested := nestedConfig(field.Type); nested != nil {
+			if err := checkConfig(nested, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/**
+Returns the struct bound from properties by the field of the type, nil if the field is a property
+*/
+func nestedConfig(typ reflect.Type) reflect.Type {
+	for {
+		switch {
+		case typ.Kind() == reflect.Struct && typ != durationClass:
+			return typ
+		case typ.Kind() == reflect.Ptr, typ.Kind() == reflect.Map, typ.Kind() == reflect.Slice && !isPropertyType(typ):
+			typ = typ.Elem()
+		default:
+			return nil
+		}
+	}
+}
+
+/**
+Options of 'config' tag of the field in format 'name,default=value,required', where default value is the last option and could contain commas
+*/
+type configOption struct {
+	name         string
+	defaultValue string
+	hasDefault   bool
+	required     bool
+}
+
+func parseConfigOption(field reflect.StructField, j int, tag string, class reflect.Type) (configOption, error) {
+	var opt configOption
+	if i := strings.Index(tag, "default="); i >= 0 {
+		opt.defaultValue = tag[i+len("default="):]
+		opt.hasDefault = true
+		tag = tag[:i]
+	}
+	for n, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case n == 0:
+			opt.name = part
+		case part == "required":
+			opt.required = true
+		case part == "":
+		default:
+			return opt, errors.Errorf("unknown option '%s' in 'config' tag of field '%s' on position %d in %v", part, field.Name, j, class)
+		}
+	}
+	if opt.name == "" {
+		opt.name = strings.ToLower(field.Name)
+	}
+	return opt, nil
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+/**
+Binds fields with 'config' tag of the struct value from properties with the key prefix, then calls ConfigValidator
+*/
+func bindConfig(value reflect.Value, prefix string, props map[string]string, isSecret func(string) bool) error {
+
+	typ := value.Type()
+	for j := 0; j < typ.NumField(); j++ {
+		field := typ.Field(j)
+		tag, ok := field.Tag.Lookup("config")
+		if !ok || field.Name == "_" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return errors.Errorf("config field '%s' in '%v' is not public", field.Name, typ)
+		}
+		opt, err := parseConfigOption(field, j, tag, typ)
+		if err != nil {
+			return err
+		}
+		if err := bindConfigValue(value.Field(j), joinKey(prefix, opt.name), opt, props, isSecret); err != nil {
+			return err
+		}
+	}
+
+	if value.CanAddr() {
+		if validator, ok := value.Addr().Interface().(ConfigValidator); ok {
+			if err := validator.Validate(); err != nil {
+				if prefix == "" {
+					return errors.Errorf("config '%v' is invalid, %v", typ, err)
+				}
+				return errors.Errorf("config '%s' is invalid, %v", prefix, err)
+			}
+		}
+	}
+	return nil
+}
+
+/**
+Assigns fields with 'config' tag of the bound struct value to the target value of the same type
+*/
+func assignConfig(target, bound reflect.Value) {
+	typ := target.Type()
+	for j := 0; j < typ.NumField(); j++ {
+		field := typ.Field(j)
+		if _, ok := field.Tag.Lookup("config"); ok && field.Name != "_" && field.PkgPath == "" {
+			target.Field(j).Set(bound.Field(j))
+		}
+	}
+}
+
+func bindConfigValue(field reflect.Value, key string, opt configOption, props map[string]string, isSecret func(string) bool) error {
+
+	typ := field.Type()
+
+	switch {
+	case typ.Kind() == reflect.Struct && typ != durationClass:
+		if opt.required && !hasKeyPrefix(props, key) {
+			return errors.Errorf("required config '%s' is missing", key)
+		}
+		return bindConfig(field, key, props, isSecret)
+
+	case typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct:
+		if !hasKeyPrefix(props, key) {
+			if opt.required {
+				return errors.Errorf("required config '%s' is missing", key)
+			}
+			return nil
+		}
+		// bind into the copy, the struct could be shared with the bean being refreshed
+		elem := reflect.New(typ.Elem())
+		if !field.IsNil() {
+			elem.Elem().Set(field.Elem())
+		}
+		if err := bindConfig(elem.Elem(), key, props, isSecret); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+
+	case typ.Kind() == reflect.Map:
+		return bindConfigMap(field, key, opt, props, isSecret)
+
+	case typ.Kind() == reflect.Slice && !isPropertyType(typ):
+		return bindConfigSlice(field, key, opt, props, isSecret)
+
+	case isPropertyType(typ):
+		s, ok := props[key]
+		if !ok && typ.Kind() == reflect.Slice && hasKeyPrefix(props, key) {
+			return bindConfigSlice(field, key, opt, props, isSecret)
+		}
+		if !ok {
+			if opt.hasDefault {
+				s = opt.defaultValue
+			} else if opt.required {
+				return errors.Errorf("required config property '%s' is missing", key)
+			} else {
+				// keep the value of the field
+				return nil
+			}
+		}
+		v, err := convertProperty(s, typ)
+		if err != nil {
+			if isSecret(key) {
+				err = errMalformedSecret
+			}
+			return errors.Errorf("config property '%s' is malformed, %v", key, err)
+		}
+		field.Set(v)
+		return nil
+
+	default:
+		return errors.Errorf("not supported config type '%v' of property '%s'", typ, key)
+	}
+}
+
+/**
+Binds map with string key, the key of map is the rest of property key for simple values or the next segment for structs
+*/
+func bindConfigMap(field reflect.Value, key string, opt configOption, props map[string]string, isSecret func(string) bool) error {
+
+	typ := field.Type()
+	if typ.Key().Kind() != reflect.String {
+		return errors.Errorf("config map '%s' must have string key, but type is '%v'", key, typ)
+	}
+
+	elemType := typ.Elem()
+	simple := isPropertyType(elemType)
+
+	names := make(map[string]bool)
+	for _, k := range childKeys(props, key) {
+		if simple {
+			names[k] = true
+		} else {
+			names[strings.SplitN(k, ".", 2)[0]] = true
+		}
+	}
+
+	if len(names) == 0 {
+		if opt.required {
+			return errors.Errorf("required config '%s' is missing", key)
+		}
+		return nil
+	}
+
+	table := reflect.MakeMapWithSize(typ, len(names))
+	for name := range names {
+		elem := reflect.New(elemType).Elem()
+		if err := bindConfigValue(elem, joinKey(key, name), configOption{required: true}, props, isSecret); err != nil {
+			return err
+		}
+		table.SetMapIndex(reflect.ValueOf(name).Convert(typ.Key()), elem)
+	}
+	field.Set(table)
+	return nil
+}
+
+/**
+Binds slice from indexed properties 'key.0', 'key.1' and so on
+*/
+func bindConfigSlice(field reflect.Value, key string, opt configOption, props map[string]string, isSecret func(string) bool) error {
+
+	typ := field.Type()
+	list := reflect.MakeSlice(typ, 0, 0)
+	for i := 0; ; i++ {
+		elemKey := joinKey(key, strconv.Itoa(i))
+		if _, ok := props[elemKey]; !ok && !hasKeyPrefix(props, elemKey) {
+			break
+		}
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := bindConfigValue(elem, elemKey, configOption{required: true}, props, isSecret); err != nil {
+			return err
+		}
+		list = reflect.Append(list, elem)
+	}
+
+	if list.Len() == 0 {
+		if opt.required {
+			return errors.Errorf("required config '%s' is missing", key)
+		}
+		return nil
+	}
+	field.Set(list)
+	return nil
+}
+
+/**
+Checks if any property starts with 'key.'
+*/
+func hasKeyPrefix(props map[string]string, key string) bool {
+	prefix := key + "."
+	for k := range props {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+Returns sorted rest of property keys started with 'key.'
+*/
+func childKeys(props map[string]string, key string) []string {
+	prefix := key + "."
+	var list []string
+	for k := range props {
+		if strings.HasPrefix(k, prefix) {
+			list = append(list, k[len(prefix):])
+		}
+	}
+	sort.Strings(list)
+	return list
+}
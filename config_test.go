@@ -0,0 +1,151 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"strings"
+	"testing"
+	"time"
+)
+
+type poolConfig struct {
+	Size    int           `config:"size,default=10"`
+	Timeout time.Duration `config:"timeout,default=30s"`
+}
+
+func (t *poolConfig) Validate() error {
+	if t.Size <= 0 {
+		return errors.Errorf("pool size must be positive, but was %d", t.Size)
+	}
+	return nil
+}
+
+type replicaConfig struct {
+	URL    string `config:"url,required"`
+	Weight int    `config:"weight,default=1"`
+}
+
+type databaseConfig struct {
+	_        struct{}                 `config:"prefix=database"`
+	URL      string                   `config:"url,required"`
+	User     string                   `config:",default=admin"`
+	Pool     poolConfig               `config:"pool"`
+	Hosts    []string                 `config:"hosts"`
+	Replicas map[string]replicaConfig `config:"replicas"`
+	Shards   []*replicaConfig         `config:"shards"`
+	Labels   map[string]string        `config:"labels"`
+	Backup   *replicaConfig           `config:"backup"`
+}
+
+type databaseService struct {
+	Config *databaseConfig `inject`
+}
+
+func TestConfigBinding(t *testing.T) {
+
+	beans.Verbose = true
+
+	service := &databaseService{}
+	ctx, err := beans.Create(
+		beans.PropertyMap(map[string]string{
+			"database.url":                  "db://main",
+			"database.pool.size":            "20",
+			"database.hosts":                "first, second",
+			"database.replicas.east.url":    "db://east",
+			"database.replicas.west.url":    "db://west",
+			"database.replicas.west.weight": "3",
+			"database.shards.0.url":         "db://shard0",
+			"database.shards.1.url":         "db://shard1",
+			"database.labels.team":          "core",
+			"database.labels.tier.name":     "gold",
+		}),
+		&databaseConfig{},
+		service,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	config := service.Config
+	require.NotNil(t, config)
+	require.Equal(t, "db://main", config.URL)
+	require.Equal(t, "admin", config.User)
+	require.Equal(t, 20, config.Pool.Size)
+	require.Equal(t, 30*time.Second, config.Pool.Timeout)
+	require.Equal(t, []string{"first", "second"}, config.Hosts)
+	require.Equal(t, 2, len(config.Replicas))
+	require.Equal(t, 1, config.Replicas["east"].Weight)
+	require.Equal(t, 3, config.Replicas["west"].Weight)
+	require.Equal(t, 2, len(config.Shards))
+	require.Equal(t, "db://shard1", config.Shards[1].URL)
+	require.Equal(t, "core", config.Labels["team"])
+	require.Equal(t, "gold", config.Labels["tier.name"])
+	require.Nil(t, config.Backup)
+}
+
+func TestConfigErrors(t *testing.T) {
+
+	_, err := beans.Create(&databaseConfig{})
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "'database.url'"), err.Error())
+
+	_, err = beans.Create(
+		beans.PropertyMap(map[string]string{
+			"database.url":       "db://main",
+			"database.pool.size": "large",
+		}),
+		&databaseConfig{},
+	)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "'database.pool.size'"), err.Error())
+
+	_, err = beans.Create(
+		beans.PropertyMap(map[string]string{
+			"database.url":       "db://main",
+			"database.pool.size": "-1",
+		}),
+		&databaseConfig{},
+	)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "'database.pool'"), err.Error())
+
+	_, err = beans.Create(
+		beans.PropertyMap(map[string]string{
+			"database.url":                  "db://main",
+			"database.replicas.east.weight": "2",
+		}),
+		&databaseConfig{},
+	)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "'database.replicas.east.url'"), err.Error())
+}
+
+type typoPoolConfig struct {
+	Size int `config:"size,requird"`
+}
+
+type typoConfig struct {
+	_    struct{}       `config:"prefix=typo"`
+	Pool typoPoolConfig `config:"pool"`
+}
+
+type typoServerConfig struct {
+	_    struct{} `config:"prefix=server"`
+	Host string   `config:"host,requird"`
+}
+
+func TestConfigUnknownOption(t *testing.T) {
+
+	_, err := beans.Create(&typoServerConfig{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown option 'requird' in 'config' tag of field 'Host'")
+
+	// nested structs are checked on scan, even without properties
+	_, err = beans.Create(&typoConfig{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown option 'requird' in 'config' tag of field 'Size'")
+}
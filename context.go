@@ -5,13 +5,17 @@
 package beans
 
 import (
+	gocontext "context"
 	"fmt"
 	"github.com/pkg/errors"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 /**
@@ -31,10 +35,41 @@ type context struct {
 
 	/**
 		All instances scanned during creation of context.
-	    No modifications on runtime allowed.
+	    Modifications on runtime allowed only by Register and Unregister.
 	*/
 	core map[reflect.Type][]*bean
 
+	/**
+	Guards core on runtime modifications
+	*/
+	coreMu sync.RWMutex
+
+	/**
+	Index of core types by method names for interface search
+	*/
+	index methodIndex
+
+	/**
+	Serializes runtime modifications of the context
+	*/
+	updateMu sync.Mutex
+
+	/**
+	Slice and map fields with 'dynamic' option updated on runtime modifications
+	*/
+	collections []*injection
+
+	/**
+	Properties loaded from property sources of the context
+	*/
+	properties *properties
+
+	/**
+	Child contexts created by Extend and not closed yet
+	*/
+	children   []*context
+	childrenMu sync.Mutex
+
 	/**
 	List of beans in initialization order that should depose on close
 	*/
@@ -46,22 +81,52 @@ type context struct {
 	registry registry
 
 	/**
-	Cache bean descriptions for Inject calls in runtime
+	Cache compiled injectors for Inject calls in runtime
 	*/
-	runtimeCache sync.Map // key is reflect.Type (classPtr), value is *beanDef
+	runtimeCache sync.Map // key is reflect.Type (classPtr), value is *runtimeInjector
+
+	/**
+	Counter of changes of beans in the context, see changed
+	*/
+	generation atomic.Uint64
 
 	/**
 	Guarantees that context would be closed once
 	*/
 	destroyOnce sync.Once
+
+	/**
+	All beans of the context were constructed, lifecycle events are published
+	*/
+	created atomic.Bool
+
+	/**
+	Lifecycle beans of the context were started and not stopped
+	*/
+	running atomic.Bool
+
+	/**
+	Runs services of the context
+	*/
+	services *supervisor
+
+	/**
+	Counters and durations of the context operations
+	*/
+	metrics metrics
+
+	/**
+	Tracer of the context operations
+	*/
+	tracing *tracing
 }
 
 func Create(scan ...interface{}) (Context, error) {
-	return createContext(nil, scan)
+	return createContext(nil, scan, createOptions{})
 }
 
 func (t *context) Extend(scan ...interface{}) (Context, error) {
-	return createContext(t, scan)
+	return createContext(t, scan, createOptions{})
 }
 
 func (t *context) Parent() (Context, bool) {
@@ -72,7 +137,26 @@ func (t *context) Parent() (Context, bool) {
 	}
 }
 
-func createContext(parent *context, scan []interface{}) (Context, error) {
+/**
+Options of the context creation used by Wired and Blueprint
+*/
+type createOptions struct {
+
+	/**
+	Fields of scanned objects are already set by the caller
+	*/
+	wired bool
+
+	/**
+	Compiled definitions of scanned types
+	*/
+	blueprint *blueprint
+}
+
+/**
+Creates the context by scanned objects
+*/
+func createContext(parent *context, scan []interface{}, opts createOptions) (Context, error) {
 
 	prev := runtime.GOMAXPROCS(1)
 	defer func() {
@@ -80,12 +164,14 @@ func createContext(parent *context, scan []interface{}) (Context, error) {
 	}()
 
 	core := make(map[reflect.Type][]*bean)
-	pointers := make(map[reflect.Type][]*injection)
-	interfaces := make(map[reflect.Type][]*injection)
 
 	ctx := &context{
 		parent: parent,
 		core:   core,
+		properties: &properties{
+			listeners: make(map[string][]*propertyListener),
+		},
+		services: newSupervisor(),
 		registry: registry{
 			beansByName: make(map[string][]*bean),
 			beansByType: make(map[reflect.Type][]*bean),
@@ -102,172 +188,380 @@ func createContext(parent *context, scan []interface{}) (Context, error) {
 	}
 	core[ctxBean.beanDef.classPtr] = []*bean {ctxBean}
 
-	// scan
-	err := forEach("", scan, func(pos string, obj interface{}) (err error) {
+	defs := newDefinitions(core)
+	defs.wired = opts.wired
+	defs.blueprint = opts.blueprint
 
-		classPtr := reflect.TypeOf(obj)
+	err := forEach("", scan, func(pos string, obj interface{}) error {
+		return defs.scanObject(pos, obj, "", nil)
+	})
 
-		defer func() {
-			if r := recover(); r != nil {
-				err = errors.Errorf("recover from object scan '%s' on error %v\n", classPtr.String(), r)
-			}
-		}()
+	if err != nil {
+		return nil, err
+	}
+	ctx.own(core)
 
-		switch classPtr.Kind() {
-		case reflect.Ptr:
-			/**
-			Create bean from object
-			*/
-			objBean, err := investigate(obj, classPtr)
-			if err != nil {
-				return err
-			}
+	if opts.blueprint != nil && opts.blueprint.sameTypes(core) {
+		defs.plan = opts.blueprint.plan
+	}
 
-			var elemClassPtr reflect.Type
-			factoryBean, isFactoryBean := obj.(FactoryBean)
-			if isFactoryBean {
-				elemClassPtr = factoryBean.ObjectType()
-			}
+	ctx.tracing = newTracing(defs.tracers, parent)
+	endTrace := ctx.traceOperation(SpanCreate, "", ContextClass)
+	err = ctx.initialize(defs)
+	endTrace(err)
+	if err != nil {
+		return nil, err
+	}
 
-			if Verbose {
-				if isFactoryBean {
-					var info string
-					if factoryBean.Singleton() {
-						info = "singleton"
-					} else {
-						info = "non-singleton"
-					}
-					objectName := factoryBean.ObjectName()
-					if objectName != "" {
-						fmt.Printf("FactoryBean %v produce %s %v with name '%s'\n", classPtr, info, elemClassPtr, objectName)
-					} else {
-						fmt.Printf("FactoryBean %v produce %s %v\n", classPtr, info, elemClassPtr)
-					}
+	ctx.launchServices()
+	return ctx, nil
+
+}
+
+/**
+Reads properties, injects, constructs and starts scanned beans, then publishes creation events
+*/
+func (t *context) initialize(defs *definitions) error {
+
+	t.properties.sources = orderBeans(defs.sources)
+	values, secrets, err := readSources(t.properties.sources)
+	if err != nil {
+		return err
+	}
+	t.properties.set(values, secrets)
+
+	if defs.wired {
+		if err := t.wireDefinitions(defs); err != nil {
+			return err
+		}
+	} else if err := t.injectDefinitions(defs, true); err != nil {
+		return err
+	}
+
+	if err := t.bindDefinitions(defs); err != nil {
+		return err
+	}
+
+	t.collections = defs.collections
+
+	if err := t.postConstruct(); err != nil {
+		t.Close()
+		return err
+	}
+
+	if err := t.startBeans(gocontext.Background()); err != nil {
+		t.Close()
+		return err
+	}
+
+	t.created.Store(true)
+	// runtime injectors compiled on construction could skip beans that were not initialized yet
+	t.changed()
+	if err := t.publishLocal(ContextCreatedEvent{Context: t}, true); err != nil {
+		t.Close()
+		return err
+	}
+
+	if t.parent != nil {
+		t.parent.addChild(t)
+		if err := t.parent.Publish(ChildContextCreatedEvent{Parent: t.parent, Child: t}); err != nil {
+			t.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+Bean definitions collected by scan
+*/
+type definitions struct {
+
+	/**
+	Scanned beans by type
+	*/
+	core map[reflect.Type][]*bean
+
+	/**
+	Injections by pointer or function type
+	*/
+	pointers map[reflect.Type][]*injection
+
+	/**
+	Injections by interface type
+	*/
+	interfaces map[reflect.Type][]*injection
+
+	/**
+	Injections of slices and maps with 'dynamic' option
+	*/
+	collections []*injection
+
+	/**
+	Scanned property sources in scan order
+	*/
+	sources []*bean
+
+	/**
+	Scanned tracers in scan order
+	*/
+	tracers []Tracer
+
+	/**
+	Injection fields are set by generated code, see Wired
+	*/
+	wired bool
+
+	/**
+	Compiled definitions of types, see Compile
+	*/
+	blueprint *blueprint
+
+	/**
+	Types of the context implementing injected interfaces, valid only if scanned types are the same as compiled
+	*/
+	plan map[reflect.Type][]reflect.Type
+
+	/**
+	Scan by Compile, multi factories are not called, since they produce objects for each created context
+	*/
+	compile bool
+}
+
+func newDefinitions(core map[reflect.Type][]*bean) *definitions {
+	return &definitions{
+		core:       core,
+		pointers:   make(map[reflect.Type][]*injection),
+		interfaces: make(map[reflect.Type][]*injection),
+	}
+}
+
+/**
+Scans the object and registers bean definitions, the name is not empty for objects produced by multi factory bean
+*/
+func (t *definitions) scanObject(pos string, obj interface{}, name string, producer *bean) (err error) {
+
+	classPtr := reflect.TypeOf(obj)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("recover from object scan '%s' on error %v\n", classPtr.String(), r)
+		}
+	}()
+
+	switch classPtr.Kind() {
+	case reflect.Ptr:
+		/**
+		Create bean from object
+		*/
+		objBean, err := t.investigate(obj, classPtr)
+		if err != nil {
+			return err
+		}
+
+		if name != "" {
+			objBean.name = name
+			objBean.qualifier = name
+		}
+
+		if producer != nil {
+			objBean.dependencies = append(objBean.dependencies, producer)
+		}
+
+		var elemClassPtr reflect.Type
+		// the adapter is resolved once on scan and kept by the factory
+		factoryBean, isFactoryBean := objBean.asFactoryBean()
+		if isFactoryBean {
+			elemClassPtr = factoryBean.ObjectType()
+		}
+
+		if Verbose {
+			if isFactoryBean {
+				var info string
+				if factoryBean.Singleton() {
+					info = "singleton"
 				} else {
-					if objBean.qualifier != "" {
-						fmt.Printf("Bean %v with name '%s'\n", classPtr, objBean.qualifier)
-					} else {
-						fmt.Printf("Bean %v\n", classPtr)
-					}
+					info = "non-singleton"
+				}
+				objectName := factoryBean.ObjectName()
+				if objectName != "" {
+					fmt.Printf("FactoryBean %v produce %s %v with name '%s'\n", classPtr, info, elemClassPtr, objectName)
+				} else {
+					fmt.Printf("FactoryBean %v produce %s %v\n", classPtr, info, elemClassPtr)
+				}
+			} else {
+				if objBean.qualifier != "" {
+					fmt.Printf("Bean %v with name '%s'\n", classPtr, objBean.qualifier)
+				} else {
+					fmt.Printf("Bean %v\n", classPtr)
 				}
 			}
+		}
 
-			if isFactoryBean {
-				elemClassKind := elemClassPtr.Kind()
-				if elemClassKind != reflect.Ptr && elemClassKind != reflect.Interface {
-					return errors.Errorf("factory bean '%v' on position '%s' can produce ptr or interface, but object type is '%v'", classPtr, pos, elemClassPtr)
-				}
+		if isFactoryBean {
+			elemClassKind := elemClassPtr.Kind()
+			if elemClassKind != reflect.Ptr && elemClassKind != reflect.Interface {
+				return errors.Errorf("factory bean '%v' on position '%s' can produce ptr or interface, but object type is '%v'", classPtr, pos, elemClassPtr)
 			}
+		}
 
-			if len(objBean.beanDef.fields) > 0 {
-				value := objBean.valuePtr.Elem()
-				for _, injectDef := range objBean.beanDef.fields {
-					if Verbose {
-						var attr []string
-						if injectDef.lazy {
-							attr = append(attr,  "lazy")
-						}
-						if injectDef.optional {
-							attr = append(attr,  "optional")
-						}
-						if injectDef.qualifier != "" {
-							attr = append(attr,  "bean=" + injectDef.qualifier)
-						}
-						var attrs string
-						if len(attr) > 0 {
-							attrs = fmt.Sprintf("[%s]", strings.Join(attr, ","))
-						}
-						var prefix string
-						if injectDef.slice {
-							prefix = "[]"
-						}
-						if injectDef.table {
-							prefix = "map[string]"
-						}
-						fmt.Printf("	Field %s%v %s\n", prefix, injectDef.fieldType, attrs)
+		if _, ok := obj.(PropertySource); ok {
+			t.sources = append(t.sources, objBean)
+		}
+
+		if tracer, ok := obj.(Tracer); ok {
+			t.tracers = append(t.tracers, tracer)
+		}
+
+		if len(objBean.beanDef.fields) > 0 {
+			value := objBean.valuePtr.Elem()
+			for _, injectDef := range objBean.beanDef.fields {
+				if Verbose {
+					var attr []string
+					if injectDef.lazy {
+						attr = append(attr,  "lazy")
 					}
-					switch injectDef.fieldType.Kind() {
-					case reflect.Ptr:
-						pointers[injectDef.fieldType] = append(pointers[injectDef.fieldType], &injection{objBean, value, injectDef})
-					case reflect.Interface:
-						interfaces[injectDef.fieldType] = append(interfaces[injectDef.fieldType], &injection{objBean, value, injectDef})
-					case reflect.Func:
-						pointers[injectDef.fieldType] = append(pointers[injectDef.fieldType], &injection{objBean, value, injectDef})
-					default:
-						return errors.Errorf("injecting not a pointer or interface on field type '%v' at position '%s' in %v", injectDef.fieldType, pos, classPtr)
+					if injectDef.optional {
+						attr = append(attr,  "optional")
 					}
+					if injectDef.dynamic {
+						attr = append(attr, "dynamic")
+					}
+					if injectDef.qualifier != "" {
+						attr = append(attr,  "bean=" + injectDef.qualifier)
+					}
+					var attrs string
+					if len(attr) > 0 {
+						attrs = fmt.Sprintf("[%s]", strings.Join(attr, ","))
+					}
+					var prefix string
+					if injectDef.slice {
+						prefix = "[]"
+					}
+					if injectDef.table {
+						prefix = "map[string]"
+					}
+					fmt.Printf("	Field %s%v %s\n", prefix, injectDef.fieldType, attrs)
 				}
-			}
-
-			/*
-				Register factory if needed
-			*/
-			if isFactoryBean {
-				f := &factory{
-					bean:            objBean,
-					factoryObj:      obj,
-					factoryClassPtr: classPtr,
-					factoryBean:     factoryBean,
-				}
-				objectName := factoryBean.ObjectName()
-				if objectName == "" {
-					objectName = elemClassPtr.String()
+				inject := &injection{objBean, value, injectDef}
+				switch injectDef.fieldType.Kind() {
+				case reflect.Ptr:
+					t.pointers[injectDef.fieldType] = append(t.pointers[injectDef.fieldType], inject)
+				case reflect.Interface:
+					t.interfaces[injectDef.fieldType] = append(t.interfaces[injectDef.fieldType], inject)
+				case reflect.Func:
+					t.pointers[injectDef.fieldType] = append(t.pointers[injectDef.fieldType], inject)
+				default:
+					return errors.Errorf("injecting not a pointer or interface on field type '%v' at position '%s' in %v", injectDef.fieldType, pos, classPtr)
 				}
-				elemBean := &bean{
-					name:        objectName,
-					beenFactory: f,
-					beanDef: &beanDef{
-						classPtr: elemClassPtr,
-					},
-					lifecycle: BeanAllocated,
+				if injectDef.dynamic {
+					t.collections = append(t.collections, inject)
 				}
-				f.instances = []*bean {elemBean}
-				// we can have singleton or multiple beans in context produced by this factory, let's allocate reference for injections even if those beans are still not exist
-				registerBean(core, elemClassPtr, elemBean)
 			}
+		}
 
-			/*
-				Register bean itself
-			*/
-			registerBean(core, classPtr, objBean)
-		case reflect.Func:
+		/*
+			Register factory if needed
+		*/
+		if isFactoryBean {
+			f := &factory{
+				bean:            objBean,
+				factoryObj:      obj,
+				factoryClassPtr: classPtr,
+				factoryBean:     factoryBean,
+			}
+			objectName := factoryBean.ObjectName()
+			if objectName == "" {
+				objectName = elemClassPtr.String()
+			}
+			elemBean := &bean{
+				name:        objectName,
+				beenFactory: f,
+				beanDef: &beanDef{
+					classPtr: elemClassPtr,
+				},
+				lifecycle: BeanAllocated,
+			}
+			f.instances = []*bean {elemBean}
+			// we can have singleton or multiple beans in context produced by this factory, let's allocate reference for injections even if those beans are still not exist
+			registerBean(t.core, elemClassPtr, elemBean)
+		}
 
+		/*
+			Register bean itself
+		*/
+		registerBean(t.core, classPtr, objBean)
+
+		/*
+			Register beans produced by multi factory
+		*/
+		if multiFactory, ok := obj.(MultiFactoryBean); ok && !t.compile {
+			objects, err := multiFactory.Objects()
+			if err != nil {
+				return errors.Errorf("multi factory bean '%v' on position '%s' failed to produce objects, %v", classPtr, pos, err)
+			}
+			names := make([]string, 0, len(objects))
+			for objectName := range objects {
+				names = append(names, objectName)
+			}
+			sort.Strings(names)
 			if Verbose {
-				fmt.Printf("Function %v\n", classPtr)
+				fmt.Printf("MultiFactoryBean %v produce %v\n", classPtr, names)
 			}
+			for i, objectName := range names {
+				if objects[objectName] == nil {
+					return errors.Errorf("multi factory bean '%v' on position '%s' produced nil object with name '%s'", classPtr, pos, objectName)
+				}
+				if err := t.scanObject(fmt.Sprintf("%s.%d", pos, i), objects[objectName], objectName, objBean); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Func:
 
-			/*
-				Register function in context
-			*/
-			registerBean(core, classPtr, &bean{
-				name:     classPtr.String(),
-				obj:      obj,
-				valuePtr: reflect.ValueOf(obj),
-				beanDef: &beanDef{
-					classPtr: classPtr,
-				},
-				lifecycle: BeanCreated,
-			})
-		default:
-			return errors.Errorf("instance could be a pointer or function, but was '%s' on position '%s' of type '%v'", classPtr.Kind().String(), pos, classPtr)
+		if Verbose {
+			fmt.Printf("Function %v\n", classPtr)
 		}
 
-		return nil
-	})
+		funcName := classPtr.String()
+		if name != "" {
+			funcName = name
+		}
 
-	if err != nil {
-		return nil, err
+		/*
+			Register function in context
+		*/
+		registerBean(t.core, classPtr, &bean{
+			name:     funcName,
+			obj:      obj,
+			valuePtr: reflect.ValueOf(obj),
+			beanDef: &beanDef{
+				classPtr: classPtr,
+			},
+			lifecycle: BeanCreated,
+		})
+	default:
+		return errors.Errorf("instance could be a pointer or function, but was '%s' on position '%s' of type '%v'", classPtr.Kind().String(), pos, classPtr)
 	}
 
+	return nil
+}
+
+/**
+Injects scanned definitions by using beans from context and parents, caches found beans in registry if needed
+*/
+func (t *context) injectDefinitions(defs *definitions, cache bool) error {
+
 	// direct match
-	for requiredType, injects := range pointers {
+	for requiredType, injects := range defs.pointers {
 
-		direct := ctx.findDirectRecursive(requiredType)
+		direct := t.findDirectRecursive(requiredType)
 		if len(direct) > 0 {
 
 			// register only beans from current context
-			if direct[0].level == 1 {
-				ctx.registry.addBeanList(requiredType, direct[0].list)
+			if cache && direct[0].level == 1 {
+				t.registry.addBeanList(requiredType, direct[0].list)
 			}
 
 			if Verbose {
@@ -276,7 +570,7 @@ func createContext(parent *context, scan []interface{}) (Context, error) {
 
 			for _, inject := range injects {
 				if err := inject.inject(direct); err != nil {
-					return nil, errors.Errorf("required type '%s' injection error, %v", requiredType, err)
+					return errors.Errorf("required type '%s' injection error, %v", requiredType, err)
 				}
 			}
 
@@ -298,16 +592,21 @@ func createContext(parent *context, scan []interface{}) (Context, error) {
 			}
 
 			if len(required) > 0 {
-				return nil, errors.Errorf("can not find candidates for '%v' reference bean required by '%+v'", requiredType, required)
+				return errors.Errorf("can not find candidates for '%v' reference bean required by '%+v'", requiredType, required)
 			}
 
 		}
 	}
 
 	// interface match
-	for ifaceType, injects := range interfaces {
+	for ifaceType, injects := range defs.interfaces {
 
-		candidates := ctx.searchCandidatesRecursive(ifaceType)
+		var candidates []beanlist
+		if defs.plan != nil {
+			candidates = t.plannedCandidatesRecursive(ifaceType, defs.plan[ifaceType])
+		} else {
+			candidates = t.searchCandidatesRecursive(ifaceType)
+		}
 		if len(candidates) == 0 {
 
 			if Verbose {
@@ -326,15 +625,15 @@ func createContext(parent *context, scan []interface{}) (Context, error) {
 			}
 
 			if len(required) > 0 {
-				return nil, errors.Errorf("can not find candidates for '%v' interface required by '%+v'", ifaceType, required)
+				return errors.Errorf("can not find candidates for '%v' interface required by '%+v'", ifaceType, required)
 			}
 
 			continue
 		}
 
 		// register beans that found only in current context
-		if candidates[0].level == 1 {
-			ctx.registry.addBeanList(ifaceType, candidates[0].list)
+		if cache && candidates[0].level == 1 {
+			t.registry.addBeanList(ifaceType, candidates[0].list)
 		}
 
 		for _, inject := range injects {
@@ -344,27 +643,21 @@ func createContext(parent *context, scan []interface{}) (Context, error) {
 			}
 
 			if err := inject.inject(candidates); err != nil {
-				return nil, errors.Errorf("interface '%s' injection error, %v", ifaceType, err)
+				return errors.Errorf("interface '%s' injection error, %v", ifaceType, err)
 			}
 
 		}
 
 	}
 
-	if err := ctx.postConstruct(); err != nil {
-		ctx.Close()
-		return nil, err
-	} else {
-		return ctx, nil
-	}
-
+	return nil
 }
 
 func (t *context) findDirectRecursive(requiredType reflect.Type) []beanlist {
 	var candidates []beanlist
 	level := 1
 	for ctx := t; ctx != nil; ctx = ctx.parent {
-		if direct, ok := ctx.core[requiredType]; ok {
+		if direct, ok := ctx.findDirect(requiredType); ok {
 			candidates = append(candidates, beanlist{level: level, list: direct})
 		}
 		level++
@@ -376,7 +669,7 @@ func (t *context) findAndCacheDirectRecursive(requiredType reflect.Type) []beanl
 	var candidates []beanlist
 	level := 1
 	for ctx := t; ctx != nil; ctx = ctx.parent {
-		if direct, ok := ctx.core[requiredType]; ok {
+		if direct, ok := ctx.findDirect(requiredType); ok {
 			candidates = append(candidates, beanlist{level: level, list: direct})
 			ctx.registry.addBeanList(requiredType, direct)
 		}
@@ -385,7 +678,29 @@ func (t *context) findAndCacheDirectRecursive(requiredType reflect.Type) []beanl
 	return candidates
 }
 
+// multi-threading safe
+func (t *context) findDirect(requiredType reflect.Type) ([]*bean, bool) {
+	t.coreMu.RLock()
+	defer t.coreMu.RUnlock()
+	list, ok := t.core[requiredType]
+	return list, ok
+}
+
+/**
+Marks scanned beans as held by the context, so they are reloaded through it
+*/
+func (t *context) own(core map[reflect.Type][]*bean) {
+	for _, list := range core {
+		for _, b := range list {
+			b.ctx = t
+		}
+	}
+}
+
+var beanSeq atomic.Uint64
+
 func registerBean(registry map[reflect.Type][]*bean, classPtr reflect.Type, bean *bean) {
+	bean.seq = beanSeq.Add(1)
 	registry[classPtr] = append(registry[classPtr], bean)
 /*
 	if list, ok := registry[classPtr]; ok {
@@ -428,6 +743,8 @@ func forEach(initialPos string, scan []interface{}, cb func(i string, obj interf
 }
 
 func (t *context) Core() []reflect.Type {
+	t.coreMu.RLock()
+	defer t.coreMu.RUnlock()
 	var list []reflect.Type
 	for typ := range t.core {
 		list = append(list, typ)
@@ -459,7 +776,15 @@ func (t *context) Lookup(iface string, level int) []Bean {
 	return beanList
 }
 
-func (t *context) Inject(obj interface{}) error {
+func (t *context) Inject(obj interface{}) (err error) {
+	t.metrics.injects.Add(1)
+	endTrace := t.traceInject(reflect.TypeOf(obj))
+	defer func() {
+		endTrace(err)
+		if err != nil {
+			t.metrics.fail(PhaseInject)
+		}
+	}()
 	if obj == nil {
 		return errors.New("null obj is are not allowed")
 	}
@@ -468,22 +793,38 @@ func (t *context) Inject(obj interface{}) error {
 		return errors.Errorf("non-pointer instances are not allowed, type %v", classPtr)
 	}
 	valuePtr := reflect.ValueOf(obj)
-	value := valuePtr.Elem()
-	if bd, err := t.cache(obj, classPtr); err != nil {
+	injector, err := t.injector(obj, classPtr)
+	if err != nil {
 		return err
-	} else {
-		for _, inject := range bd.fields {
-			impl := t.getBean(inject.fieldType)
-			if len(impl) > 0 {
-				if err := inject.inject(&value, impl); err != nil {
-					return err
-				}
-			} else {
-				return errors.Errorf("implementation not found for field '%s' with type '%v'", inject.fieldName, inject.fieldType)
-			}
+	}
+	if err := injector.inject(t, valuePtr); err != nil {
+		return err
+	}
+	return t.bindProperties(valuePtr, injector.beanDef)
+}
+
+func (t *context) Build(obj interface{}) (h Handle, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("build runtime object with type '%v' recovered with error %v", reflect.TypeOf(obj), r)
 		}
+	}()
+
+	if err := t.Inject(obj); err != nil {
+		return nil, err
 	}
-	return nil
+
+	if initializer, ok := obj.(InitializingBean); ok {
+		if Verbose {
+			fmt.Printf("PostConstruct runtime object with type '%v'\n", reflect.TypeOf(obj))
+		}
+		if err := initializer.PostConstruct(); err != nil {
+			return nil, errors.Errorf("post construct failed for runtime object with type '%v', %v", reflect.TypeOf(obj), err)
+		}
+	}
+
+	return &handle{obj: obj}, nil
 }
 
 // multi-threading safe
@@ -492,8 +833,10 @@ func (t *context) getBean(ifaceType reflect.Type) []beanlist {
 	// search in cache
 	list := t.searchInRepositoryRecursive(ifaceType)
 	if len(list) > 0 {
+		t.metrics.cacheHits.Add(1)
 		return list
 	}
+	t.metrics.cacheMisses.Add(1)
 
 	// unknown entity request, le't search and cache it
 	switch ifaceType.Kind() {
@@ -532,20 +875,6 @@ func (t *context) searchByNameInRepositoryRecursive(iface string) []beanlist {
 	return candidates
 }
 
-// multi-threading safe
-func (t *context) cache(obj interface{}, classPtr reflect.Type) (*beanDef, error) {
-	if bd, ok := t.runtimeCache.Load(classPtr); ok {
-		return bd.(*beanDef), nil
-	} else {
-		b, err := investigate(obj, classPtr)
-		if err != nil {
-			return nil, err
-		}
-		t.runtimeCache.Store(classPtr, b.beanDef)
-		return b.beanDef, nil
-	}
-}
-
 func getStackInfo(stack []*bean, delim string) string {
 	var out strings.Builder
 	n := len(stack)
@@ -589,17 +918,23 @@ func indent(n int) string {
 
 func (t *context) constructBean(bean *bean, stack []*bean) (err error) {
 
+	if bean.lifecycle == BeanInitialized {
+		return nil
+	}
+
+	endTrace := t.traceConstruct(bean, stack)
+	defer func() {
+		endTrace(err)
+	}()
+
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.Errorf("construct bean '%s' with type '%v' recovered with error %v", bean.name, bean.beanDef.classPtr, r)
+			t.metrics.fail(PhaseConstruct)
 		}
 	}()
 
-	if bean.lifecycle == BeanInitialized {
-		return nil
-	}
-
-	_, isFactoryBean := bean.obj.(FactoryBean)
+	isFactoryBean := bean.beanDef.factory
 	initializer, hasConstructor := bean.obj.(InitializingBean)
 	if Verbose {
 		fmt.Printf("%sConstruct Bean '%s' with type '%v', isFactoryBean=%v, hasFactory=%v, hasObject=%v, hasConstructor=%v\n", indent(len(stack)), bean.name, bean.beanDef.classPtr, isFactoryBean, bean.beenFactory != nil, bean.obj != nil, hasConstructor)
@@ -626,11 +961,19 @@ func (t *context) constructBean(bean *bean, stack []*bean) (err error) {
 		if Verbose {
 			fmt.Printf("%sFactoryDep (%v).Object()\n", indent(len(stack)+1), factoryDep.factory.factoryClassPtr)
 		}
-		bean, created, err := factoryDep.factory.ctor()
+		endCall := noTrace
+		if !factoryDep.factory.cached() {
+			endCall = t.traceCall(SpanObject, factoryDep.factory.bean, bean)
+		}
+		start := time.Now()
+		bean, created, err := factoryDep.factory.ctor(factoryDep.point)
+		endCall(err)
 		if err != nil {
+			t.metrics.fail(PhaseConstruct)
 			return errors.Errorf("factory ctor '%v' failed, %v", factoryDep.factory.factoryClassPtr, err)
 		}
 		if created {
+			t.observeConstruct(bean, start)
 			if Verbose {
 				fmt.Printf("%sDep Created Bean %s with type '%v'\n", indent(len(stack)+1), bean.name, bean.beanDef.classPtr)
 			}
@@ -655,13 +998,18 @@ func (t *context) constructBean(bean *bean, stack []*bean) (err error) {
 		if Verbose {
 			fmt.Printf("%s(%v).Object()\n", indent(len(stack)), bean.beenFactory.factoryClassPtr)
 		}
-		_, _, err := bean.beenFactory.ctor() // always new
+		endCall := t.traceCall(SpanObject, bean.beenFactory.bean, bean)
+		start := time.Now()
+		_, _, err := bean.beenFactory.ctor(nil) // always new
+		endCall(err)
 		if err != nil {
+			t.metrics.fail(PhaseConstruct)
 			return errors.Errorf("factory ctor '%v' failed, %v", bean.beenFactory.factoryClassPtr, err)
 		}
 		if bean.obj == nil {
 			return errors.Errorf("bean '%v' was not created by factory ctor '%v'", bean, bean.beenFactory.factoryClassPtr)
 		}
+		t.observeConstruct(bean, start)
 		return nil
 	}
 
@@ -669,9 +1017,15 @@ func (t *context) constructBean(bean *bean, stack []*bean) (err error) {
 		if Verbose {
 			fmt.Printf("%sPostConstruct Bean '%s' with type '%v'\n", indent(len(stack)), bean.name, bean.beanDef.classPtr)
 		}
-		if err := initializer.PostConstruct(); err != nil {
+		endCall := t.traceCall(SpanPostConstruct, bean, bean)
+		start := time.Now()
+		err := initializer.PostConstruct()
+		endCall(err)
+		if err != nil {
+			t.metrics.fail(PhaseConstruct)
 			return errors.Errorf("post construct failed %s, %v", getStackInfo(reverseStack(append(stack, bean)), " required by "), err)
 		}
+		t.observeConstruct(bean, start)
 	}
 
 	t.addDisposable(bean)
@@ -712,10 +1066,36 @@ func (t *context) Close() (err error) {
 
 	var listErr []error
 	t.destroyOnce.Do(func() {
+		// beans are not changed by Register, Replace or reload while destroyed
+		t.updateMu.Lock()
+		defer t.updateMu.Unlock()
+		endTrace := t.traceOperation(SpanClose, "", ContextClass)
+		defer func() {
+			t.changed()
+			endTrace(multipleErr(listErr))
+		}()
+		t.changed()
+		if t.parent != nil {
+			t.parent.removeChild(t)
+		}
+		if t.created.Load() {
+			if e := t.publishLocal(ContextClosingEvent{Context: t}, true); e != nil {
+				listErr = append(listErr, e)
+			}
+		}
+		t.cancelServices()
+		if e := t.stopBeans(gocontext.Background()); e != nil {
+			listErr = append(listErr, e)
+		}
 		n := len(t.disposables)
 		for j := n - 1; j >= 0; j-- {
 			t.destroyBean(t.disposables[j])
 		}
+		if t.created.Load() {
+			if e := t.publishLocal(ContextClosedEvent{Context: t}, false); e != nil {
+				listErr = append(listErr, e)
+			}
+		}
 	})
 	return multipleErr(listErr)
 }
@@ -726,6 +1106,9 @@ func (t *context) destroyBean(b *bean) (err error) {
 		if r := recover(); r != nil {
 			err = errors.Errorf("destroy bean '%s' with type '%v' recovered with error: %v", b.name, b.beanDef.classPtr, r)
 		}
+		if err != nil {
+			t.metrics.fail(PhaseDestroy)
+		}
 	}()
 
 	if b.lifecycle != BeanInitialized {
@@ -737,11 +1120,15 @@ func (t *context) destroyBean(b *bean) (err error) {
 		fmt.Printf("Destroy bean '%s' with type '%v'\n", b.name, b.beanDef.classPtr)
 	}
 	if dis, ok := b.obj.(DisposableBean); ok {
+		endCall := t.traceCall(SpanDestroy, b, nil)
+		start := time.Now()
 		if e := dis.Destroy(); e != nil {
 			err = e
 		} else {
 			b.lifecycle = BeanDestroyed
 		}
+		t.metrics.destroy.observe(time.Since(start))
+		endCall(err)
 	}
 	return
 }
@@ -787,19 +1174,35 @@ func (t *context) searchAndCacheCandidatesRecursive(ifaceType reflect.Type) []be
 }
 
 func (t *context) searchCandidates(ifaceType reflect.Type) []*bean {
+	t.coreMu.RLock()
+	defer t.coreMu.RUnlock()
 	var candidates []*bean
-	for _, list := range t.core {
-		if len(list) > 0 && list[0].beanDef.implements(ifaceType) {
+	for _, classPtr := range t.index.candidates(ifaceType, t.core) {
+		list := t.core[classPtr]
+		if isCandidate(classPtr, list, ifaceType) {
 			candidates = append(candidates, list...)
 		}
 	}
 	return candidates
 }
 
+var contextClassPtr = reflect.TypeOf((*context)(nil))
+
+/**
+Checks if beans of the type implement the interface. The context bean is the candidate only of Context and EventPublisher,
+other interfaces matched by methods of the context like Start, Stop or Wait belong to user beans.
+*/
+func isCandidate(classPtr reflect.Type, list []*bean, ifaceType reflect.Type) bool {
+	if len(list) == 0 || !list[0].beanDef.implements(ifaceType) {
+		return false
+	}
+	return classPtr != contextClassPtr || ifaceType == ContextClass || ifaceType == EventPublisherClass
+}
+
 func searchByInterface(ifaceType reflect.Type, core map[reflect.Type][]*bean) ([]*bean, error) {
 	var candidates [][]*bean
-	for _, list := range core {
-		if len(list) > 0 && list[0].beanDef.implements(ifaceType) {
+	for classPtr, list := range core {
+		if isCandidate(classPtr, list, ifaceType) {
 			candidates = append(candidates, list)
 		}
 	}
@@ -0,0 +1,50 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+/**
+Published to listeners of the context after all beans of the context were constructed.
+The error of listener fails creation of the context.
+*/
+type ContextCreatedEvent struct {
+	Context Context
+}
+
+/**
+Published to listeners of the context after Refresh changed properties of the context
+*/
+type ContextRefreshedEvent struct {
+	Context     Context
+	ChangedKeys []string
+}
+
+/**
+Published to listeners of the context on Close before any bean is destroyed
+*/
+type ContextClosingEvent struct {
+	Context Context
+}
+
+/**
+Published to listeners of the context on Close after all beans were destroyed, listeners receive it even if they were destroyed
+*/
+type ContextClosedEvent struct {
+	Context Context
+}
+
+/**
+Published to listeners of the parent context and its parents after the child context was created by Extend
+*/
+type ChildContextCreatedEvent struct {
+	Parent Context
+	Child  Context
+}
+
+/**
+Published to listeners of the context and its parents after the bean was reloaded by Reload, ReloadCascade, ReloadFactory or replaced by Replace
+*/
+type BeanReloadedEvent struct {
+	Bean Bean
+}
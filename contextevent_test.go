@@ -0,0 +1,134 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"testing"
+)
+
+type lifecycleJournal struct {
+	records []string
+}
+
+type lifecycleStorage struct {
+	Journal *lifecycleJournal `inject`
+}
+
+func (t *lifecycleStorage) PostConstruct() error {
+	t.Journal.records = append(t.Journal.records, "storage:construct")
+	return nil
+}
+
+func (t *lifecycleStorage) Destroy() error {
+	t.Journal.records = append(t.Journal.records, "storage:destroy")
+	return nil
+}
+
+type lifecycleServer struct {
+	Journal *lifecycleJournal `inject`
+	Storage *lifecycleStorage `inject`
+}
+
+func (t *lifecycleServer) OnEvent(event beans.ContextCreatedEvent) error {
+	t.Journal.records = append(t.Journal.records, "server:listen")
+	return nil
+}
+
+type lifecycleShutdown struct {
+	Journal *lifecycleJournal `inject`
+}
+
+func (t *lifecycleShutdown) OnEvent(event beans.ContextClosingEvent) error {
+	t.Journal.records = append(t.Journal.records, "server:shutdown")
+	return nil
+}
+
+type lifecycleClosed struct {
+	Journal *lifecycleJournal `inject`
+}
+
+func (t *lifecycleClosed) OnEvent(event beans.ContextClosedEvent) error {
+	t.Journal.records = append(t.Journal.records, "closed")
+	return nil
+}
+
+type lifecycleAudit struct {
+	Journal *lifecycleJournal `inject`
+}
+
+func (t *lifecycleAudit) OnEvent(event interface{}) error {
+	switch e := event.(type) {
+	case beans.ChildContextCreatedEvent:
+		t.Journal.records = append(t.Journal.records, "audit:child")
+	case beans.ContextRefreshedEvent:
+		t.Journal.records = append(t.Journal.records, fmt.Sprintf("audit:refresh:%v", e.ChangedKeys))
+	case beans.BeanReloadedEvent:
+		t.Journal.records = append(t.Journal.records, "audit:reload:"+e.Bean.Name())
+	}
+	return nil
+}
+
+func TestContextEvents(t *testing.T) {
+
+	beans.Verbose = true
+
+	journal := &lifecycleJournal{}
+	source := &lockedSource{values: map[string]string{"server.port": "8080"}}
+	ctx, err := beans.Create(
+		journal,
+		source,
+		&lifecycleServer{},
+		&lifecycleStorage{},
+		&lifecycleShutdown{},
+		&lifecycleClosed{},
+		&lifecycleAudit{},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"storage:construct", "server:listen"}, journal.records)
+
+	journal.records = nil
+	child, err := ctx.Extend()
+	require.NoError(t, err)
+	require.Equal(t, []string{"audit:child"}, journal.records)
+	require.NoError(t, child.Close())
+
+	journal.records = nil
+	source.set("server.port", "9090")
+	require.NoError(t, ctx.Refresh())
+	require.Equal(t, []string{"audit:refresh:[server.port]"}, journal.records)
+
+	journal.records = nil
+	storage := ctx.Lookup("*beans_test.lifecycleStorage", beans.DefaultLevel)
+	require.Equal(t, 1, len(storage))
+	require.NoError(t, ctx.ReloadCascade(storage[0]))
+	require.Equal(t, []string{"storage:destroy", "storage:construct", "audit:reload:*beans_test.lifecycleStorage"}, journal.records)
+
+	journal.records = nil
+	require.NoError(t, ctx.Close())
+	require.Equal(t, []string{"server:shutdown", "storage:destroy", "closed"}, journal.records)
+}
+
+type failingStartListener struct {
+}
+
+func (t *failingStartListener) OnEvent(event beans.ContextCreatedEvent) error {
+	return errors.New("port is busy")
+}
+
+func TestContextCreatedFailure(t *testing.T) {
+
+	journal := &lifecycleJournal{}
+	_, err := beans.Create(
+		journal,
+		&lifecycleStorage{},
+		&failingStartListener{},
+	)
+	require.Error(t, err)
+	require.Equal(t, []string{"storage:construct", "storage:destroy"}, journal.records)
+}
@@ -0,0 +1,88 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"testing"
+)
+
+var scopedLoggerClass = reflect.TypeOf((*scopedLogger)(nil))
+
+type scopedLogger struct {
+	scope string
+}
+
+type scopedLoggerFactory struct {
+	produced int
+}
+
+func (t *scopedLoggerFactory) Object() (interface{}, error) {
+	t.produced++
+	return &scopedLogger{scope: "default"}, nil
+}
+
+func (t *scopedLoggerFactory) ObjectFor(point beans.InjectionPoint) (interface{}, error) {
+	t.produced++
+	scope := point.Tag.Get("scope")
+	if scope == "" {
+		scope = point.Class.String() + "." + point.FieldName
+	}
+	return &scopedLogger{scope: scope}, nil
+}
+
+func (t *scopedLoggerFactory) ObjectType() reflect.Type {
+	return scopedLoggerClass
+}
+
+func (t *scopedLoggerFactory) ObjectName() string {
+	return ""
+}
+
+func (t *scopedLoggerFactory) Singleton() bool {
+	return false
+}
+
+type firstLoggerConsumer struct {
+	Log *scopedLogger `inject`
+}
+
+type secondLoggerConsumer struct {
+	Log *scopedLogger `inject:"" scope:"audit"`
+}
+
+type runtimeLoggerConsumer struct {
+	Log *scopedLogger `inject:"bean=*beans_test.scopedLogger" scope:"request"`
+}
+
+func TestContextualFactoryBean(t *testing.T) {
+
+	beans.Verbose = true
+
+	first := &firstLoggerConsumer{}
+	second := &secondLoggerConsumer{}
+
+	ctx, err := beans.Create(
+		&scopedLoggerFactory{},
+		first,
+		second,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.NotNil(t, first.Log)
+	require.Equal(t, "*beans_test.firstLoggerConsumer.Log", first.Log.scope)
+
+	require.NotNil(t, second.Log)
+	require.Equal(t, "audit", second.Log.scope)
+
+	rt := &runtimeLoggerConsumer{}
+	err = ctx.Inject(rt)
+	require.NoError(t, err)
+	require.NotNil(t, rt.Log)
+	require.Equal(t, "request", rt.Log.scope)
+}
@@ -0,0 +1,501 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	gocontext "context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+func (t *context) addChild(child *context) {
+	t.childrenMu.Lock()
+	defer t.childrenMu.Unlock()
+	t.children = append(t.children, child)
+}
+
+func (t *context) removeChild(child *context) {
+	t.childrenMu.Lock()
+	defer t.childrenMu.Unlock()
+	var list []*context
+	for _, ctx := range t.children {
+		if ctx != child {
+			list = append(list, ctx)
+		}
+	}
+	t.children = list
+}
+
+/**
+Returns current context and all child contexts recursively
+*/
+func (t *context) descendants() []*context {
+	list := []*context{t}
+	t.childrenMu.Lock()
+	children := append([]*context(nil), t.children...)
+	t.childrenMu.Unlock()
+	for _, child := range children {
+		list = append(list, child.descendants()...)
+	}
+	return list
+}
+
+/**
+Returns all beans registered in core of the context
+*/
+func (t *context) coreBeans() []*bean {
+	t.coreMu.RLock()
+	defer t.coreMu.RUnlock()
+	var list []*bean
+	for _, beans := range t.core {
+		list = append(list, beans...)
+	}
+	return list
+}
+
+func (t *context) Register(scan ...interface{}) (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("register beans recovered with error %v", r)
+		}
+	}()
+
+	defs := newDefinitions(make(map[reflect.Type][]*bean))
+
+	err = forEach("", scan, func(pos string, obj interface{}) error {
+		return defs.scanObject(pos, obj, "", nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	t.own(defs.core)
+
+	var added []*bean
+	t.coreMu.Lock()
+	for classPtr, list := range defs.core {
+		t.core[classPtr] = append(t.core[classPtr], list...)
+		added = append(added, list...)
+	}
+	t.index.invalidate()
+	t.changed()
+	t.coreMu.Unlock()
+
+	if err := t.injectDefinitions(defs, false); err != nil {
+		t.removeCore(added)
+		return err
+	}
+
+	if err := t.bindDefinitions(defs); err != nil {
+		t.removeCore(added)
+		return err
+	}
+
+	disposables := len(t.disposables)
+	if err := t.constructBeanList(added, nil); err != nil {
+		t.discardRegistered(added, disposables)
+		return err
+	}
+
+	for _, b := range added {
+		if Verbose {
+			fmt.Printf("Register bean '%s' with type '%v'\n", b.name, b.beanDef.classPtr)
+		}
+		t.registerCached(b)
+	}
+
+	var started []*bean
+	for _, b := range initOrder(added) {
+		if _, ok := b.obj.(Lifecycle); ok && t.running.Load() && b.lifecycle == BeanInitialized {
+			if err := startBean(gocontext.Background(), b); err != nil {
+				t.metrics.fail(PhaseLifecycle)
+				for j := len(started) - 1; j >= 0; j-- {
+					stopBean(gocontext.Background(), started[j])
+				}
+				t.discardRegistered(added, disposables)
+				return err
+			}
+			started = append(started, b)
+		}
+	}
+
+	for _, b := range initOrder(added) {
+		if _, ok := b.obj.(Service); ok && t.created.Load() && b.lifecycle == BeanInitialized {
+			t.launchService(b)
+		}
+	}
+
+	t.collections = append(t.collections, defs.collections...)
+	return t.updateCollections()
+}
+
+/**
+Rolls back failed registration, destroys constructed beans in reverse order and removes added beans from the context
+*/
+func (t *context) discardRegistered(added []*bean, disposables int) {
+	n := len(t.disposables)
+	for j := n - 1; j >= disposables; j-- {
+		t.destroyBean(t.disposables[j])
+	}
+	t.disposables = t.disposables[:disposables]
+	t.removeCore(added)
+	for _, b := range added {
+		t.registry.removeBean(b)
+	}
+}
+
+func (t *context) Unregister(instance Bean) (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("unregister bean recovered with error %v", r)
+		}
+	}()
+
+	b, ok := instance.(*bean)
+	if !ok || !t.hasCoreBean(b) {
+		return errors.Errorf("bean '%v' is not registered in context", instance)
+	}
+
+	if b.obj == t {
+		return errors.New("context bean can not be unregistered")
+	}
+
+	if b.beenFactory != nil {
+		return errors.Errorf("bean '%v' was created by factory bean '%v', unregister the factory bean instead", b, b.beenFactory.factoryClassPtr)
+	}
+
+	// remove factory bean together with produced beans
+	removed := []*bean{b}
+	for _, el := range t.coreBeans() {
+		if el.beenFactory != nil && el.beenFactory.bean == b {
+			removed = append(removed, el.beenFactory.instances...)
+			break
+		}
+	}
+
+	for _, el := range removed {
+		if dependents := t.dependents(el, removed); len(dependents) > 0 {
+			return errors.Errorf("bean '%v' can not be unregistered, because it is required by %v", el, dependents)
+		}
+	}
+
+	var listErr []error
+	n := len(removed)
+	for j := n - 1; j >= 0; j-- {
+		if _, ok := removed[j].obj.(Service); ok {
+			t.cancelService(removed[j])
+		}
+		if _, ok := removed[j].obj.(Lifecycle); ok {
+			if e := stopBean(gocontext.Background(), removed[j]); e != nil {
+				listErr = append(listErr, e)
+			}
+		}
+		// objects produced by factory are not destroyed by context
+		if removed[j].beenFactory == nil {
+			if e := t.destroyBean(removed[j]); e != nil {
+				// the bean is removed, but stays in BeanDestroying, since Destroy did not complete
+				listErr = append(listErr, e)
+			} else {
+				removed[j].lifecycle = BeanDestroyed
+			}
+		} else {
+			removed[j].lifecycle = BeanDestroyed
+		}
+		if Verbose {
+			fmt.Printf("Unregister bean '%s' with type '%v'\n", removed[j].name, removed[j].beanDef.classPtr)
+		}
+	}
+
+	t.removeCore(removed)
+
+	for _, el := range removed {
+		t.registry.removeBean(el)
+		t.disposables = removeFromList(t.disposables, el)
+	}
+
+	for _, ctx := range t.descendants() {
+		var collections []*injection
+		for _, inject := range ctx.collections {
+			if !containsBean(removed, inject.bean) {
+				collections = append(collections, inject)
+			}
+		}
+		ctx.collections = collections
+		for _, consumer := range ctx.coreBeans() {
+			for _, el := range removed {
+				consumer.dependencies = removeFromList(consumer.dependencies, el)
+			}
+		}
+	}
+
+	if e := t.updateCollections(); e != nil {
+		listErr = append(listErr, e)
+	}
+
+	return multipleErr(listErr)
+}
+
+func (t *context) hasCoreBean(b *bean) bool {
+	list, _ := t.findDirect(b.beanDef.classPtr)
+	return containsBean(list, b)
+}
+
+func containsBean(list []*bean, b *bean) bool {
+	for _, el := range list {
+		if el == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *context) removeCore(list []*bean) {
+	t.coreMu.Lock()
+	defer t.coreMu.Unlock()
+	defer t.index.invalidate()
+	defer t.changed()
+	for _, b := range list {
+		classPtr := b.beanDef.classPtr
+		if rest := removeFromList(t.core[classPtr], b); len(rest) > 0 {
+			t.core[classPtr] = rest
+		} else {
+			delete(t.core, classPtr)
+		}
+	}
+}
+
+/**
+Adds bean registered on runtime to all types cached in registry of current, parent or child contexts,
+lookups from child contexts pass through the registry of this context and find the bean there
+*/
+func (t *context) registerCached(b *bean) {
+	chain := t.descendants()
+	for ctx := t.parent; ctx != nil; ctx = ctx.parent {
+		chain = append(chain, ctx)
+	}
+	for _, ctx := range chain {
+		for _, ifaceType := range ctx.registry.types() {
+			if ifaceType == b.beanDef.classPtr || (ifaceType.Kind() == reflect.Interface && b.beanDef.implements(ifaceType)) {
+				t.cacheType(ifaceType, b)
+			}
+		}
+	}
+}
+
+/**
+Adds the bean to the cached type, caches all beans of the type in this context if the type was not cached yet
+*/
+func (t *context) cacheType(ifaceType reflect.Type, b *bean) {
+	if _, ok := t.registry.findByType(ifaceType); ok {
+		t.registry.addNewBean(ifaceType, b)
+		return
+	}
+	var list []*bean
+	if ifaceType.Kind() == reflect.Interface {
+		list = t.searchCandidates(ifaceType)
+	} else {
+		list, _ = t.findDirect(ifaceType)
+	}
+	for _, el := range list {
+		t.registry.addNewBean(ifaceType, el)
+	}
+}
+
+/**
+Updates collections with 'dynamic' option in current and child contexts
+*/
+func (t *context) updateCollections() error {
+	for _, ctx := range t.descendants() {
+		for _, inject := range ctx.collections {
+			if err := ctx.updateCollection(inject); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *context) updateCollection(inject *injection) error {
+
+	def := inject.injectionDef
+
+	var deep []beanlist
+	if def.fieldType.Kind() == reflect.Interface {
+		deep = t.searchCandidatesRecursive(def.fieldType)
+	} else {
+		deep = t.findDirectRecursive(def.fieldType)
+	}
+
+	var list []*bean
+	if len(deep) > 0 {
+		for _, b := range def.filterBeans(orderBeans(levelBeans(deep, def.level))) {
+			// skip beans that are not produced by factory yet
+			if b.valuePtr.IsValid() {
+				list = append(list, b)
+			}
+		}
+	}
+
+	field := inject.value.Field(def.fieldNum)
+
+	if def.slice {
+		newSlice := reflect.MakeSlice(field.Type(), 0, len(list))
+		for _, b := range list {
+			newSlice = reflect.Append(newSlice, b.valuePtr)
+		}
+		field.Set(newSlice)
+		return nil
+	}
+
+	newMap := reflect.MakeMapWithSize(field.Type(), len(list))
+	for _, b := range list {
+		key := reflect.ValueOf(b.name)
+		if newMap.MapIndex(key).IsValid() {
+			return errors.Errorf("can not inject duplicates '%s' to the map field '%s' in class '%v'", b.name, def.fieldName, def.class)
+		}
+		newMap.SetMapIndex(key, b.valuePtr)
+	}
+	field.Set(newMap)
+	return nil
+}
+
+/**
+Field of the bean that holds the reference on another bean
+*/
+type reference struct {
+	bean         *bean
+	injectionDef *injectionDef
+}
+
+func (t reference) String() string {
+	return fmt.Sprintf("%v->%s", t.bean.beanDef.classPtr, t.injectionDef.fieldName)
+}
+
+/**
+Finds non-lazy fields in current and child contexts that hold the object of the target bean, except fields of beans in the skip list.
+Collections with 'dynamic' option are not dependents, since they are updated on removal.
+*/
+func (t *context) dependents(target *bean, skip []*bean) []reference {
+	var list []reference
+	for _, ctx := range t.descendants() {
+		for _, consumer := range ctx.coreBeans() {
+			if containsBean(skip, consumer) {
+				continue
+			}
+			for _, ref := range consumer.references(target) {
+				if !ref.injectionDef.lazy && !ref.injectionDef.dynamic {
+					list = append(list, ref)
+				}
+			}
+		}
+	}
+	return list
+}
+
+/**
+Finds fields of the bean that hold the object of the target bean
+*/
+func (t *bean) references(target *bean) []reference {
+	if t.beanDef.fields == nil || !target.valuePtr.IsValid() || !t.valuePtr.IsValid() {
+		return nil
+	}
+	var list []reference
+	value := t.valuePtr.Elem()
+	for _, def := range t.beanDef.fields {
+		field := value.Field(def.fieldNum)
+		found := false
+		switch {
+		case def.slice:
+			for i := 0; i < field.Len() && !found; i++ {
+				found = sameObject(field.Index(i), target.valuePtr)
+			}
+		case def.table:
+			iter := field.MapRange()
+			for iter.Next() && !found {
+				found = sameObject(iter.Value(), target.valuePtr)
+			}
+		default:
+			found = sameObject(field, target.valuePtr)
+		}
+		if found {
+			list = append(list, reference{bean: t, injectionDef: def})
+		}
+	}
+	return list
+}
+
+/**
+Compares pointers of the field and object, functions are compared by code pointer
+*/
+func sameObject(field reflect.Value, obj reflect.Value) bool {
+	if field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return false
+		}
+		field = field.Elem()
+	}
+	if obj.Kind() == reflect.Interface {
+		obj = obj.Elem()
+	}
+	if field.Kind() != obj.Kind() || field.Type() != obj.Type() {
+		return false
+	}
+	switch field.Kind() {
+	case reflect.Ptr, reflect.Func:
+		return !field.IsNil() && field.Pointer() == obj.Pointer()
+	default:
+		return false
+	}
+}
+
+/**
+Replaces the old object by the new one in the field.
+Slices and maps held by the field are not modified, the field gets the updated copy, so readers iterating the previous one are not affected.
+Pointer and map fields are updated atomically, slice and interface fields are multi-word values and updated by plain stores.
+*/
+func (t reference) replace(prev, next reflect.Value) {
+	field := t.bean.valuePtr.Elem().Field(t.injectionDef.fieldNum)
+	switch {
+	case t.injectionDef.slice:
+		slice := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		reflect.Copy(slice, field)
+		for i := 0; i < slice.Len(); i++ {
+			if sameObject(slice.Index(i), prev) {
+				slice.Index(i).Set(next)
+			}
+		}
+		field.Set(slice)
+	case t.injectionDef.table:
+		table := reflect.MakeMapWithSize(field.Type(), field.Len())
+		iter := field.MapRange()
+		for iter.Next() {
+			if sameObject(iter.Value(), prev) {
+				table.SetMapIndex(iter.Key(), next)
+			} else {
+				table.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		storeObject(field, table)
+	default:
+		storeObject(field, next)
+	}
+}
+
+func storeObject(field, obj reflect.Value) {
+	if (field.Kind() == reflect.Ptr || field.Kind() == reflect.Map) && field.Type() == obj.Type() && field.CanAddr() {
+		atomicSet(field, obj)
+	} else {
+		field.Set(obj)
+	}
+}
@@ -0,0 +1,186 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var PluginClass = reflect.TypeOf((*Plugin)(nil)).Elem()
+
+type Plugin interface {
+	beans.NamedBean
+	Run() string
+}
+
+type pluginRuntime struct {
+	calls int
+}
+
+type pluginImpl struct {
+	Runtime     *pluginRuntime `inject`
+	name        string
+	constructed bool
+	destroyed   bool
+}
+
+func (t *pluginImpl) BeanName() string {
+	return t.name
+}
+
+func (t *pluginImpl) Run() string {
+	t.Runtime.calls++
+	return t.name
+}
+
+func (t *pluginImpl) PostConstruct() error {
+	t.constructed = true
+	return nil
+}
+
+func (t *pluginImpl) Destroy() error {
+	t.destroyed = true
+	return nil
+}
+
+type pluginHost struct {
+	Plugins []Plugin          `inject:"optional,dynamic"`
+	ByName  map[string]Plugin `inject:"optional,dynamic"`
+}
+
+type pluginUser struct {
+	Plugin Plugin `inject:"bean=audit"`
+}
+
+func TestRegisterUnregister(t *testing.T) {
+
+	beans.Verbose = true
+
+	host := &pluginHost{}
+	ctx, err := beans.Create(
+		&pluginRuntime{},
+		host,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, 0, len(host.Plugins))
+	require.Equal(t, 0, len(ctx.Bean(PluginClass, beans.DefaultLevel)))
+
+	audit := &pluginImpl{name: "audit"}
+	err = ctx.Register(audit, &pluginImpl{name: "metrics"})
+	require.NoError(t, err)
+
+	require.True(t, audit.constructed)
+	require.NotNil(t, audit.Runtime)
+	require.Equal(t, 2, len(host.Plugins))
+	require.Equal(t, 2, len(host.ByName))
+	require.Equal(t, "audit", host.ByName["audit"].Run())
+
+	list := ctx.Bean(PluginClass, beans.DefaultLevel)
+	require.Equal(t, 2, len(list))
+
+	user := &pluginUser{}
+	err = ctx.Register(user)
+	require.NoError(t, err)
+	require.Equal(t, audit, user.Plugin)
+
+	auditBean := ctx.Lookup("audit", beans.DefaultLevel)
+	require.Equal(t, 1, len(auditBean))
+
+	// pluginUser requires audit plugin
+	err = ctx.Unregister(auditBean[0])
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "required by"))
+	require.False(t, audit.destroyed)
+
+	err = ctx.Unregister(ctx.Bean(reflect.TypeOf(user), beans.DefaultLevel)[0])
+	require.NoError(t, err)
+
+	err = ctx.Unregister(auditBean[0])
+	require.NoError(t, err)
+	require.True(t, audit.destroyed)
+	require.Equal(t, beans.BeanDestroyed, auditBean[0].Lifecycle())
+
+	require.Equal(t, 1, len(host.Plugins))
+	require.Equal(t, "metrics", host.Plugins[0].Run())
+	require.Equal(t, 1, len(ctx.Bean(PluginClass, beans.DefaultLevel)))
+	require.Equal(t, 0, len(ctx.Lookup("audit", beans.DefaultLevel)))
+
+	err = ctx.Unregister(auditBean[0])
+	require.Error(t, err)
+}
+
+func TestRegisterMissingDependency(t *testing.T) {
+
+	ctx, err := beans.Create()
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	err = ctx.Register(&pluginImpl{name: "broken"})
+	require.Error(t, err)
+	require.Equal(t, 0, len(ctx.Bean(PluginClass, beans.DefaultLevel)))
+}
+
+func TestDynamicNotCollection(t *testing.T) {
+
+	_, err := beans.Create(&struct {
+		Plugin Plugin `inject:"dynamic"`
+	}{})
+	require.Error(t, err)
+}
+
+type failingPlugin struct {
+	pluginImpl
+}
+
+func (t *failingPlugin) Destroy() error {
+	return errors.New("plugin is busy")
+}
+
+func TestUnregisterDestroyFailure(t *testing.T) {
+
+	ctx, err := beans.Create(&pluginRuntime{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	err = ctx.Register(&failingPlugin{pluginImpl{name: "busy"}})
+	require.NoError(t, err)
+
+	busyClass := reflect.TypeOf((*failingPlugin)(nil))
+	list := ctx.Bean(busyClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	err = ctx.Unregister(list[0])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "plugin is busy")
+	require.Equal(t, beans.BeanDestroying, list[0].Lifecycle())
+	require.Equal(t, 0, len(ctx.Bean(busyClass, beans.DefaultLevel)))
+}
+
+func TestRegisterVisibleInChild(t *testing.T) {
+
+	parent, err := beans.Create(&pluginRuntime{})
+	require.NoError(t, err)
+	defer parent.Close()
+
+	child, err := parent.Extend(&pluginImpl{name: "local"})
+	require.NoError(t, err)
+	defer child.Close()
+
+	// the child caches the interface while the parent has no plugins
+	require.Equal(t, 1, len(child.Bean(PluginClass, beans.DefaultLevel)))
+
+	err = parent.Register(&pluginImpl{name: "audit"})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(child.Lookup("audit", beans.DefaultLevel)))
+	require.Equal(t, 1, len(parent.Lookup("audit", beans.DefaultLevel)))
+}
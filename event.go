@@ -0,0 +1,92 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+)
+
+func (t *context) Publish(event interface{}) error {
+	if event == nil {
+		return errors.New("null event is not allowed")
+	}
+	return publishEvent(reflect.ValueOf(event), t.eventListeners(reflect.TypeOf(event)))
+}
+
+/**
+Delivers the event only to listeners of this context, destroyed listeners are included if initialized is false
+*/
+func (t *context) publishLocal(event interface{}, initialized bool) error {
+	return publishEvent(reflect.ValueOf(event), t.contextListeners(reflect.TypeOf(event), initialized))
+}
+
+func publishEvent(event reflect.Value, listeners []*bean) error {
+	var listErr []error
+	for _, b := range listeners {
+		if err := notifyListener(b, event); err != nil {
+			listErr = append(listErr, err)
+		}
+	}
+	return multipleErr(listErr)
+}
+
+func (t *context) PublishAsync(event interface{}) <-chan error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- t.Publish(event)
+		close(ch)
+	}()
+	return ch
+}
+
+/**
+Returns initialized listeners of the event type in this and parent contexts
+*/
+func (t *context) eventListeners(eventType reflect.Type) []*bean {
+	var list []*bean
+	for ctx := t; ctx != nil; ctx = ctx.parent {
+		list = append(list, ctx.contextListeners(eventType, true)...)
+	}
+	return list
+}
+
+/**
+Returns ordered listeners of the event type in this context, listeners without order follow in scan order
+*/
+func (t *context) contextListeners(eventType reflect.Type, initialized bool) []*bean {
+	var list []*bean
+	for _, b := range t.coreBeans() {
+		bd := b.beanDef
+		if bd.eventType != nil && (b.lifecycle == BeanInitialized || !initialized) && eventType.AssignableTo(bd.eventType) {
+			list = append(list, b)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].seq < list[j].seq
+	})
+	return orderBeans(list)
+}
+
+func notifyListener(b *bean, event reflect.Value) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("listener '%s' recovered on event '%v' with error %v", b.name, event.Type(), r)
+		}
+	}()
+
+	if Verbose {
+		fmt.Printf("Publish event '%v' to listener '%s'\n", event.Type(), b.name)
+	}
+
+	out := b.valuePtr.Method(b.beanDef.eventMethod).Call([]reflect.Value{event})
+	if e, ok := out[0].Interface().(error); ok && e != nil {
+		return errors.Errorf("listener '%s' failed on event '%v', %v", b.name, event.Type(), e)
+	}
+	return nil
+}
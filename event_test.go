@@ -0,0 +1,177 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"strings"
+	"testing"
+)
+
+type orderPlaced struct {
+	id string
+}
+
+type orderCancelled struct {
+	id string
+}
+
+type orderEvent interface {
+	orderID() string
+}
+
+func (t *orderCancelled) orderID() string {
+	return t.id
+}
+
+type eventJournal struct {
+	records []string
+}
+
+type orderListener struct {
+	Journal *eventJournal `inject`
+	name    string
+	order   int
+	fail    bool
+}
+
+func (t *orderListener) BeanName() string {
+	return t.name
+}
+
+func (t *orderListener) BeanOrder() int {
+	return t.order
+}
+
+func (t *orderListener) OnEvent(event orderPlaced) error {
+	t.Journal.records = append(t.Journal.records, t.name+":"+event.id)
+	if t.fail {
+		return errors.New("listener failed")
+	}
+	return nil
+}
+
+type orderEventListener struct {
+	Journal *eventJournal `inject`
+}
+
+func (t *orderEventListener) OnEvent(event orderEvent) error {
+	t.Journal.records = append(t.Journal.records, "any:"+event.orderID())
+	return nil
+}
+
+type orderService struct {
+	Publisher beans.EventPublisher `inject`
+}
+
+var _ beans.EventListener[orderPlaced] = (*orderListener)(nil)
+
+func TestEvents(t *testing.T) {
+
+	beans.Verbose = true
+
+	journal := &eventJournal{}
+	service := &orderService{}
+	ctx, err := beans.Create(
+		journal,
+		&orderListener{name: "second", order: 2},
+		&orderListener{name: "first", order: 1},
+		&orderEventListener{},
+		service,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.NoError(t, service.Publisher.Publish(orderPlaced{id: "1"}))
+	require.Equal(t, []string{"first:1", "second:1"}, journal.records)
+
+	journal.records = nil
+	require.NoError(t, service.Publisher.Publish(&orderCancelled{id: "2"}))
+	require.Equal(t, []string{"any:2"}, journal.records)
+
+	// nobody listens
+	require.NoError(t, ctx.Publish("text"))
+	require.Error(t, ctx.Publish(nil))
+
+	journal.records = nil
+	err = <-service.Publisher.PublishAsync(orderPlaced{id: "3"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first:3", "second:3"}, journal.records)
+}
+
+func TestEventsChildContext(t *testing.T) {
+
+	journal := &eventJournal{}
+	ctx, err := beans.Create(
+		journal,
+		&orderListener{name: "parent", order: 1},
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	child, err := ctx.Extend(&orderListener{name: "child", order: 2, fail: true})
+	require.NoError(t, err)
+	defer child.Close()
+
+	err = child.Publish(orderPlaced{id: "1"})
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "listener failed"))
+	require.Equal(t, []string{"child:1", "parent:1"}, journal.records)
+
+	// parent does not deliver events to child listeners
+	journal.records = nil
+	require.NoError(t, ctx.Publish(orderPlaced{id: "2"}))
+	require.Equal(t, []string{"parent:2"}, journal.records)
+}
+
+type mailerListener struct {
+	Journal *eventJournal `inject`
+}
+
+func (t *mailerListener) OnEvent(event orderPlaced) error {
+	t.Journal.records = append(t.Journal.records, "mailer:"+event.id)
+	return nil
+}
+
+type auditListener struct {
+	Journal *eventJournal `inject`
+}
+
+func (t *auditListener) OnEvent(event orderPlaced) error {
+	t.Journal.records = append(t.Journal.records, "audit:"+event.id)
+	return nil
+}
+
+type billingListener struct {
+	Journal *eventJournal `inject`
+}
+
+func (t *billingListener) OnEvent(event orderPlaced) error {
+	t.Journal.records = append(t.Journal.records, "billing:"+event.id)
+	return nil
+}
+
+func TestEventsUnorderedListeners(t *testing.T) {
+
+	journal := &eventJournal{}
+	ctx, err := beans.Create(
+		journal,
+		&mailerListener{},
+		&auditListener{},
+		&orderListener{name: "first", order: 1},
+		&billingListener{},
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	// ordered listeners go first, others follow in scan order on every publish
+	for i := 0; i < 10; i++ {
+		journal.records = nil
+		require.NoError(t, ctx.Publish(orderPlaced{id: "1"}))
+		require.Equal(t, []string{"first:1", "mailer:1", "audit:1", "billing:1"}, journal.records)
+	}
+}
@@ -18,3 +18,91 @@ func GetBean[T any](context Context, typ reflect.Type) (ret T, ok bool) {
 	return
 }
 
+/**
+Typed alternative of FactoryBean, the type of produced object is inferred from T that can be pointer or interface.
+
+Scanned instance that implements Factory[T] is registered in context the same way as FactoryBean.
+
+Example:
+	type dataSourceFactory struct {
+		Config *config `inject`
+	}
+
+	func (t *dataSourceFactory) Object() (*sql.DB, error) {
+		return sql.Open(t.Config.Driver, t.Config.URL)
+	}
+
+	func (t *dataSourceFactory) ObjectName() string {
+		return ""
+	}
+
+	func (t *dataSourceFactory) Singleton() bool {
+		return true
+	}
+*/
+type Factory[T any] interface {
+
+	/**
+	returns an object produced by the factory
+	*/
+	Object() (T, error)
+
+	/**
+	returns the bean name of object that this Factory produces or empty string if name not defined
+	*/
+	ObjectName() string
+
+	/**
+	denotes if the object produced by this Factory is a singleton
+	*/
+	Singleton() bool
+}
+
+/**
+Adapts the function to the singleton Factory[T] that could be used in scan list
+*/
+func FactoryFunc[T any](fn func() (T, error)) Factory[T] {
+	return &funcFactory[T]{fn: fn}
+}
+
+type funcFactory[T any] struct {
+	fn func() (T, error)
+}
+
+func (t *funcFactory[T]) Object() (T, error) {
+	return t.fn()
+}
+
+func (t *funcFactory[T]) ObjectName() string {
+	return ""
+}
+
+func (t *funcFactory[T]) Singleton() bool {
+	return true
+}
+
+/**
+Event listener receives events of type E published by EventPublisher in the context or child contexts.
+
+Any scanned bean with the method 'OnEvent(E) error' is the listener, E could be a struct, a pointer or an interface,
+in the last case the listener receives all events assignable to it.
+
+Example:
+	type userCreated struct {
+		Name string
+	}
+
+	type welcomeMailer struct {
+	}
+
+	func (t *welcomeMailer) OnEvent(event userCreated) error {
+		return t.send(event.Name)
+	}
+*/
+type EventListener[E any] interface {
+
+	/**
+	Handles the event, the error is returned to the publisher
+	*/
+	OnEvent(event E) error
+}
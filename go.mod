@@ -1,6 +1,6 @@
 module go.arpabet.com/beans
 
-go 1.18
+go 1.21
 
 require (
 	github.com/pkg/errors v0.9.1
@@ -0,0 +1,44 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sync"
+)
+
+/**
+Handle of the runtime object that is not part of the context
+*/
+
+type handle struct {
+	obj       interface{}
+	closeOnce sync.Once
+}
+
+func (t *handle) Object() interface{} {
+	return t.obj
+}
+
+func (t *handle) Close() (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("destroy runtime object with type '%v' recovered with error: %v", reflect.TypeOf(t.obj), r)
+		}
+	}()
+
+	t.closeOnce.Do(func() {
+		if dis, ok := t.obj.(DisposableBean); ok {
+			if Verbose {
+				fmt.Printf("Destroy runtime object with type '%v'\n", reflect.TypeOf(t.obj))
+			}
+			err = dis.Destroy()
+		}
+	})
+	return
+}
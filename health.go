@@ -0,0 +1,174 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	gocontext "context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var DefaultHealthTimeout = 5 * time.Second
+
+/**
+Aggregated result of health checks for the probe
+*/
+type HealthReport struct {
+	Probe  HealthProbe   `json:"probe"`
+	State  HealthState   `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+/**
+Returns true if the report is not down
+*/
+func (t *HealthReport) Up() bool {
+	return t.State != HealthDown
+}
+
+/**
+Result of the health check of the single bean
+*/
+type HealthCheck struct {
+	/**
+	Name of the bean, 'context' for the state of the context itself
+	*/
+	Bean string `json:"bean"`
+	/**
+	Type of the bean
+	*/
+	Type string `json:"type"`
+	HealthStatus
+	Duration time.Duration `json:"duration"`
+}
+
+func (t *context) CheckHealth(ctx gocontext.Context, probe HealthProbe) *HealthReport {
+
+	report := &HealthReport{
+		Probe:  probe,
+		State:  HealthUp,
+		Checks: []HealthCheck{t.contextHealth(probe)},
+	}
+
+	indicators := t.healthIndicators(probe)
+	results := make([]HealthCheck, len(indicators))
+	done := make(chan struct{}, len(indicators))
+	for i, b := range indicators {
+		go func(i int, b *bean) {
+			results[i] = checkHealth(ctx, b)
+			done <- struct{}{}
+		}(i, b)
+	}
+	for range indicators {
+		<-done
+	}
+
+	report.Checks = append(report.Checks, results...)
+	for _, check := range report.Checks {
+		if check.State == HealthDown {
+			report.State = HealthDown
+		}
+	}
+	return report
+}
+
+/**
+State of the context: readiness is down if context is not created or closing, liveness is down if service escalated the failure
+*/
+func (t *context) contextHealth(probe HealthProbe) HealthCheck {
+	check := HealthCheck{
+		Bean:         "context",
+		Type:         ContextClass.String(),
+		HealthStatus: HealthStatus{State: HealthUp},
+	}
+	if err := t.Err(); err != nil && probe&LivenessProbe != 0 {
+		check.State = HealthDown
+		check.Details = map[string]interface{}{"error": err.Error()}
+		return check
+	}
+	if probe&ReadinessProbe != 0 {
+		select {
+		case <-t.Done():
+			check.State = HealthDown
+			check.Details = map[string]interface{}{"error": "context is closing"}
+		default:
+			if !t.created.Load() {
+				check.State = HealthDown
+				check.Details = map[string]interface{}{"error": "context is not created"}
+			}
+		}
+	}
+	return check
+}
+
+/**
+Returns initialized health indicators of this and parent contexts that take part in the probe
+*/
+func (t *context) healthIndicators(probe HealthProbe) []*bean {
+	var list []*bean
+	visited := make(map[*bean]bool)
+	for ctx := t; ctx != nil; ctx = ctx.parent {
+		for _, b := range initOrder(ctx.coreBeans()) {
+			if visited[b] || b.lifecycle != BeanInitialized {
+				continue
+			}
+			visited[b] = true
+			indicator, ok := b.obj.(HealthIndicator)
+			if !ok {
+				continue
+			}
+			probes := ReadinessProbe
+			if probed, ok := indicator.(ProbedHealthIndicator); ok {
+				probes = probed.Probes()
+			}
+			if probes&probe != 0 {
+				list = append(list, b)
+			}
+		}
+	}
+	return list
+}
+
+/**
+Runs the health check of the bean bounded by DefaultHealthTimeout, the check that did not finish in time is down
+*/
+func checkHealth(ctx gocontext.Context, b *bean) HealthCheck {
+
+	check := HealthCheck{
+		Bean: b.name,
+		Type: reflect.TypeOf(b.obj).String(),
+	}
+
+	ctx, cancel := gocontext.WithTimeout(ctx, DefaultHealthTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan HealthStatus, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- HealthStatus{State: HealthDown, Details: map[string]interface{}{"error": fmt.Sprintf("recovered with error %v", r)}}
+			}
+		}()
+		result <- b.obj.(HealthIndicator).Health(ctx)
+	}()
+
+	select {
+	case status := <-result:
+		check.HealthStatus = status
+		if check.State == "" {
+			check.State = HealthUnknown
+		}
+	case <-ctx.Done():
+		check.HealthStatus = HealthStatus{State: HealthDown, Details: map[string]interface{}{"error": ctx.Err().Error()}}
+	}
+	check.Duration = time.Since(start)
+
+	if Verbose {
+		fmt.Printf("Health of bean '%s' is %s in %v\n", b.name, check.State, check.Duration)
+	}
+	return check
+}
@@ -0,0 +1,143 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type databaseHealth struct {
+	down atomic.Bool
+}
+
+func (t *databaseHealth) Health(ctx context.Context) beans.HealthStatus {
+	if t.down.Load() {
+		return beans.HealthStatus{State: beans.HealthDown, Details: map[string]interface{}{"error": "connection refused"}}
+	}
+	return beans.HealthStatus{State: beans.HealthUp}
+}
+
+type slowHealth struct {
+}
+
+func (t *slowHealth) Health(ctx context.Context) beans.HealthStatus {
+	<-ctx.Done()
+	return beans.HealthStatus{State: beans.HealthUp}
+}
+
+type deadlockHealth struct {
+}
+
+func (t *deadlockHealth) Health(ctx context.Context) beans.HealthStatus {
+	return beans.HealthStatus{State: beans.HealthUp}
+}
+
+func (t *deadlockHealth) Probes() beans.HealthProbe {
+	return beans.LivenessProbe
+}
+
+type panicHealth struct {
+}
+
+func (t *panicHealth) Health(ctx context.Context) beans.HealthStatus {
+	panic("broken")
+}
+
+func findCheck(report *beans.HealthReport, name string) (beans.HealthCheck, bool) {
+	for _, check := range report.Checks {
+		if check.Bean == name {
+			return check, true
+		}
+	}
+	return beans.HealthCheck{}, false
+}
+
+func TestHealthProbes(t *testing.T) {
+
+	db := &databaseHealth{}
+	ctx, err := beans.Create(db, &deadlockHealth{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	readiness := ctx.CheckHealth(context.Background(), beans.ReadinessProbe)
+	require.True(t, readiness.Up())
+	_, ok := findCheck(readiness, "*beans_test.deadlockHealth")
+	require.False(t, ok)
+	check, ok := findCheck(readiness, "*beans_test.databaseHealth")
+	require.True(t, ok)
+	require.Equal(t, beans.HealthUp, check.State)
+
+	liveness := ctx.CheckHealth(context.Background(), beans.LivenessProbe)
+	require.True(t, liveness.Up())
+	_, ok = findCheck(liveness, "*beans_test.databaseHealth")
+	require.False(t, ok)
+	_, ok = findCheck(liveness, "*beans_test.deadlockHealth")
+	require.True(t, ok)
+
+	db.down.Store(true)
+
+	readiness = ctx.CheckHealth(context.Background(), beans.ReadinessProbe)
+	require.False(t, readiness.Up())
+	check, _ = findCheck(readiness, "*beans_test.databaseHealth")
+	require.Equal(t, beans.HealthDown, check.State)
+	require.Equal(t, "connection refused", check.Details["error"])
+
+	require.True(t, ctx.CheckHealth(context.Background(), beans.LivenessProbe).Up())
+}
+
+func TestHealthTimeout(t *testing.T) {
+
+	timeout := beans.DefaultHealthTimeout
+	beans.DefaultHealthTimeout = 20 * time.Millisecond
+	defer func() {
+		beans.DefaultHealthTimeout = timeout
+	}()
+
+	ctx, err := beans.Create(&slowHealth{}, &panicHealth{}, &databaseHealth{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	report := ctx.CheckHealth(context.Background(), beans.ReadinessProbe)
+	require.False(t, report.Up())
+
+	check, _ := findCheck(report, "*beans_test.slowHealth")
+	require.Equal(t, beans.HealthDown, check.State)
+	require.Equal(t, context.DeadlineExceeded.Error(), check.Details["error"])
+
+	check, _ = findCheck(report, "*beans_test.panicHealth")
+	require.Equal(t, beans.HealthDown, check.State)
+
+	check, _ = findCheck(report, "*beans_test.databaseHealth")
+	require.Equal(t, beans.HealthUp, check.State)
+}
+
+func TestHealthParent(t *testing.T) {
+
+	db := &databaseHealth{}
+	parent, err := beans.Create(db)
+	require.NoError(t, err)
+	defer parent.Close()
+
+	child, err := parent.Extend(&deadlockHealth{})
+	require.NoError(t, err)
+
+	db.down.Store(true)
+
+	report := child.CheckHealth(context.Background(), beans.LivenessProbe|beans.ReadinessProbe)
+	require.False(t, report.Up())
+	require.Equal(t, 3, len(report.Checks))
+	_, ok := findCheck(report, "*beans_test.databaseHealth")
+	require.True(t, ok)
+
+	require.NoError(t, child.Close())
+	report = child.CheckHealth(context.Background(), beans.ReadinessProbe)
+	check, _ := findCheck(report, "context")
+	require.Equal(t, beans.HealthDown, check.State)
+}
@@ -0,0 +1,95 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"reflect"
+	"sync"
+)
+
+/**
+Index of bean types in the context by names of exported methods.
+Candidates of the interface are only types having its rarest exported method, so Implements is not called for every type in the context.
+*/
+type methodIndex struct {
+	mu sync.RWMutex
+
+	/**
+	Index is built lazily on the first search and dropped on changes of the context
+	*/
+	valid bool
+
+	/**
+	Types by method name
+	*/
+	byMethod map[string][]reflect.Type
+
+	/**
+	All types, candidates of interfaces without exported methods
+	*/
+	all []reflect.Type
+}
+
+/**
+Drops the index, caller holds the write lock of core
+*/
+func (t *methodIndex) invalidate() {
+	t.mu.Lock()
+	t.valid = false
+	t.byMethod = nil
+	t.all = nil
+	t.mu.Unlock()
+}
+
+/**
+Returns types that could implement the interface, caller holds the read lock of core
+*/
+func (t *methodIndex) candidates(ifaceType reflect.Type, core map[reflect.Type][]*bean) []reflect.Type {
+
+	t.mu.RLock()
+	if !t.valid {
+		t.mu.RUnlock()
+		t.build(core)
+		t.mu.RLock()
+	}
+	defer t.mu.RUnlock()
+
+	candidates := t.all
+	for i := 0; i < ifaceType.NumMethod(); i++ {
+		m := ifaceType.Method(i)
+		if m.PkgPath != "" {
+			// unexported methods are not in method sets of concrete types
+			continue
+		}
+		list := t.byMethod[m.Name]
+		if len(list) < len(candidates) {
+			candidates = list
+			if len(candidates) == 0 {
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+func (t *methodIndex) build(core map[reflect.Type][]*bean) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.valid {
+		return
+	}
+	byMethod := make(map[string][]reflect.Type)
+	all := make([]reflect.Type, 0, len(core))
+	for classPtr := range core {
+		all = append(all, classPtr)
+		for i := 0; i < classPtr.NumMethod(); i++ {
+			name := classPtr.Method(i).Name
+			byMethod[name] = append(byMethod[name], classPtr)
+		}
+	}
+	t.byMethod = byMethod
+	t.all = all
+	t.valid = true
+}
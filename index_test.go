@@ -0,0 +1,284 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"testing"
+)
+
+type indexedCodec interface {
+	Encode(s string) string
+}
+
+var indexedCodecClass = reflect.TypeOf((*indexedCodec)(nil)).Elem()
+
+type indexedSealed interface {
+	Encode(s string) string
+	sealed()
+}
+
+var indexedSealedClass = reflect.TypeOf((*indexedSealed)(nil)).Elem()
+
+type indexedBase64 struct {
+}
+
+func (t *indexedBase64) Encode(s string) string {
+	return "base64:" + s
+}
+
+func (t *indexedBase64) sealed() {
+}
+
+type indexedHex struct {
+}
+
+func (t *indexedHex) Encode(s string) string {
+	return "hex:" + s
+}
+
+type indexedDecoder struct {
+}
+
+func (t *indexedDecoder) Decode(s string) string {
+	return s
+}
+
+type indexedConsumer struct {
+	Codecs []indexedCodec `inject`
+	Sealed indexedSealed  `inject`
+}
+
+type indexedCodecs struct {
+	Codecs []indexedCodec `inject`
+}
+
+func TestMethodIndex(t *testing.T) {
+
+	ctx, err := beans.Create(&indexedBase64{}, &indexedDecoder{}, &indexedConsumer{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(indexedSealedClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	consumer := ctx.Bean(reflect.TypeOf((*indexedConsumer)(nil)), beans.DefaultLevel)[0].Object().(*indexedConsumer)
+	require.Equal(t, 1, len(consumer.Codecs))
+	require.Equal(t, "base64:a", consumer.Sealed.Encode("a"))
+
+	// index is rebuilt after runtime registration
+	require.NoError(t, ctx.Register(&indexedHex{}))
+	codecs := &indexedCodecs{}
+	child, err := ctx.Extend(codecs)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(codecs.Codecs))
+
+	hex := ctx.Bean(reflect.TypeOf((*indexedHex)(nil)), beans.DefaultLevel)
+	require.Equal(t, 1, len(hex))
+	require.NoError(t, child.Close())
+	require.NoError(t, ctx.Unregister(hex[0]))
+
+	codecs = &indexedCodecs{}
+	child, err = ctx.Extend(codecs)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(codecs.Codecs))
+	require.NoError(t, child.Close())
+}
+
+/**
+Bean of the benchmark context with methods, instantiations by digits of the number are distinct types
+*/
+type indexedComponent[A, B, C, D any] struct {
+}
+
+func (t *indexedComponent[A, B, C, D]) Open() error {
+	return nil
+}
+
+func (t *indexedComponent[A, B, C, D]) Flush() error {
+	return nil
+}
+
+func (t *indexedComponent[A, B, C, D]) Len() int {
+	return 0
+}
+
+type indexedDigit0 struct {
+}
+
+type indexedDigit1 struct {
+}
+
+type indexedDigit2 struct {
+}
+
+type indexedDigit3 struct {
+}
+
+type indexedDigit4 struct {
+}
+
+type indexedDigit5 struct {
+}
+
+type indexedDigit6 struct {
+}
+
+type indexedDigit7 struct {
+}
+
+type indexedDigit8 struct {
+}
+
+type indexedDigit9 struct {
+}
+
+/**
+Appends 10000 components of distinct types, digits of the number are added from the highest one
+*/
+func indexedComponents(scan []interface{}) []interface{} {
+	scan = indexedComponents1[indexedDigit0](scan)
+	scan = indexedComponents1[indexedDigit1](scan)
+	scan = indexedComponents1[indexedDigit2](scan)
+	scan = indexedComponents1[indexedDigit3](scan)
+	scan = indexedComponents1[indexedDigit4](scan)
+	scan = indexedComponents1[indexedDigit5](scan)
+	scan = indexedComponents1[indexedDigit6](scan)
+	scan = indexedComponents1[indexedDigit7](scan)
+	scan = indexedComponents1[indexedDigit8](scan)
+	scan = indexedComponents1[indexedDigit9](scan)
+	return scan
+}
+
+func indexedComponents1[A any](scan []interface{}) []interface{} {
+	scan = indexedComponents2[A, indexedDigit0](scan)
+	scan = indexedComponents2[A, indexedDigit1](scan)
+	scan = indexedComponents2[A, indexedDigit2](scan)
+	scan = indexedComponents2[A, indexedDigit3](scan)
+	scan = indexedComponents2[A, indexedDigit4](scan)
+	scan = indexedComponents2[A, indexedDigit5](scan)
+	scan = indexedComponents2[A, indexedDigit6](scan)
+	scan = indexedComponents2[A, indexedDigit7](scan)
+	scan = indexedComponents2[A, indexedDigit8](scan)
+	scan = indexedComponents2[A, indexedDigit9](scan)
+	return scan
+}
+
+func indexedComponents2[A, B any](scan []interface{}) []interface{} {
+	scan = indexedComponents3[A, B, indexedDigit0](scan)
+	scan = indexedComponents3[A, B, indexedDigit1](scan)
+	scan = indexedComponents3[A, B, indexedDigit2](scan)
+	scan = indexedComponents3[A, B, indexedDigit3](scan)
+	scan = indexedComponents3[A, B, indexedDigit4](scan)
+	scan = indexedComponents3[A, B, indexedDigit5](scan)
+	scan = indexedComponents3[A, B, indexedDigit6](scan)
+	scan = indexedComponents3[A, B, indexedDigit7](scan)
+	scan = indexedComponents3[A, B, indexedDigit8](scan)
+	scan = indexedComponents3[A, B, indexedDigit9](scan)
+	return scan
+}
+
+func indexedComponents3[A, B, C any](scan []interface{}) []interface{} {
+	return append(scan,
+		&indexedComponent[A, B, C, indexedDigit0]{},
+		&indexedComponent[A, B, C, indexedDigit1]{},
+		&indexedComponent[A, B, C, indexedDigit2]{},
+		&indexedComponent[A, B, C, indexedDigit3]{},
+		&indexedComponent[A, B, C, indexedDigit4]{},
+		&indexedComponent[A, B, C, indexedDigit5]{},
+		&indexedComponent[A, B, C, indexedDigit6]{},
+		&indexedComponent[A, B, C, indexedDigit7]{},
+		&indexedComponent[A, B, C, indexedDigit8]{},
+		&indexedComponent[A, B, C, indexedDigit9]{},
+	)
+}
+
+/**
+Creates the context with one codec and n beans of distinct types having methods
+*/
+func indexedContext(b *testing.B, n int) beans.Context {
+	scan := []interface{}{&indexedBase64{}}
+	scan = append(scan, indexedComponents(nil)[:n]...)
+	ctx, err := beans.Create(scan...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return ctx
+}
+
+type indexedRequest struct {
+	Codec indexedCodec `inject`
+}
+
+type indexedUnexported interface {
+	sealed()
+}
+
+/**
+Interface without exported methods is checked against every type of the context, as the search without the index
+*/
+type unindexedRequest struct {
+	Sealed indexedUnexported `inject`
+}
+
+/**
+Registration in the child context resolves the interface in the parent context with many beans on every call
+*/
+func BenchmarkInterfaceSearch(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("indexed/beans=%d", n), func(b *testing.B) {
+			benchmarkRegister(b, n, func() interface{} {
+				return &indexedRequest{}
+			})
+		})
+		b.Run(fmt.Sprintf("unindexed/beans=%d", n), func(b *testing.B) {
+			benchmarkRegister(b, n, func() interface{} {
+				return &unindexedRequest{}
+			})
+		})
+	}
+}
+
+func benchmarkRegister(b *testing.B, n int, request func() interface{}) {
+	ctx := indexedContext(b, n)
+	defer ctx.Close()
+	child, err := ctx.Extend()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer child.Close()
+	requestClass := reflect.TypeOf(request())
+	// build the index before measurement
+	child.Bean(indexedCodecClass, beans.DefaultLevel)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := child.Register(request()); err != nil {
+			b.Fatal(err)
+		}
+		for _, bean := range child.Bean(requestClass, beans.DefaultLevel) {
+			if err := child.Unregister(bean); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+/**
+Creation and close of the context with many beans
+*/
+func BenchmarkCreate(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("beans=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				indexedContext(b, n).Close()
+			}
+		})
+	}
+}
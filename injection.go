@@ -32,6 +32,10 @@ type injectionDef struct {
 	*/
 	fieldType reflect.Type
 	/**
+	Tags of the field
+	*/
+	tag reflect.StructTag
+	/**
 	Field is Slice of beans
 	*/
 	slice bool
@@ -47,6 +51,10 @@ type injectionDef struct {
 	Optional injection
 	*/
 	optional bool
+	/**
+	Collection is updated on runtime registration and removal of beans
+	*/
+	dynamic bool
 	/*
 	Injection expects the specific bean to be injected
 	*/
@@ -129,7 +137,7 @@ func orderBeans(candidates []*bean) []*bean {
 	}
 	n := len(ordered)
 	if n > 0 {
-		sort.Slice(ordered, func(i, j int) bool {
+		sort.SliceStable(ordered, func(i, j int) bool {
 			return ordered[i].order < ordered[j].order
 		})
 		if n != len(candidates) {
@@ -196,8 +204,9 @@ func (t *injection) inject(deep []beanlist) error {
 			t.bean.factoryDependencies = append(t.bean.factoryDependencies,
 				&factoryDependency{
 					factory: instance.beenFactory,
+					point:   t.injectionDef.injectionPoint(),
 					injection: func(service *bean) error {
-						field.Set(reflect.Append(field, instance.valuePtr))
+						field.Set(reflect.Append(field, service.valuePtr))
 						return nil
 					},
 				})
@@ -217,6 +226,7 @@ func (t *injection) inject(deep []beanlist) error {
 				t.bean.factoryDependencies = append(t.bean.factoryDependencies,
 					&factoryDependency{
 						factory: impl.beenFactory,
+						point:   t.injectionDef.injectionPoint(),
 						injection: func(service *bean) error {
 							if visited[service.name] {
 								return errors.Errorf("can not inject duplicates '%s' to the map field '%s' in class '%v' by injecting factory bean '%v'", impl.name, t.injectionDef.fieldName, t.injectionDef.class, service.obj)
@@ -258,6 +268,7 @@ func (t *injection) inject(deep []beanlist) error {
 		t.bean.factoryDependencies = append(t.bean.factoryDependencies,
 			&factoryDependency{
 				factory: impl.beenFactory,
+				point:   t.injectionDef.injectionPoint(),
 				injection: func(service *bean) error {
 					field.Set(service.valuePtr)
 					return nil
@@ -277,23 +288,26 @@ func (t *injection) inject(deep []beanlist) error {
 	return nil
 }
 
-//atomic.StoreUintptr((*uintptr)(unsafe.Pointer(field.Addr().Pointer())), impl.valuePtr.Pointer())
+/**
+Stores the pointer of the instance in to the addressable pointer or map field atomically, readers see the old or the new pointer
+*/
 func atomicSet(field reflect.Value, instance reflect.Value) {
-	atomic.StoreUintptr((*uintptr)(unsafe.Pointer(field.Addr().Pointer())), instance.Pointer())
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(field.UnsafeAddr())), instance.UnsafePointer())
 }
 
 // runtime injection
 func (t *injectionDef) inject(value *reflect.Value, deep []beanlist) error {
 
-	list := orderBeans(levelBeans(deep, t.level))
-
 	field := value.Field(t.fieldNum)
 
 	if !field.CanSet() {
 		return errors.Errorf("field '%s' in class '%v' is not public", t.fieldName, t.class)
 	}
 
-	list = t.filterBeans(list)
+	var list []*bean
+	if len(deep) > 0 {
+		list = distinctFactoryBeans(t.filterBeans(orderBeans(levelBeans(deep, t.level))))
+	}
 
 	if len(list) == 0 {
 		if !t.optional {
@@ -308,13 +322,13 @@ func (t *injectionDef) inject(value *reflect.Value, deep []beanlist) error {
 
 	if t.slice {
 
-		newSlice := field
-		for _, bean := range list {
-			if !bean.valuePtr.IsValid() {
-				newSlice = reflect.Append(newSlice, reflect.Zero(t.fieldType))
-			} else {
-				newSlice = reflect.Append(newSlice, bean.valuePtr)
+		newSlice := reflect.MakeSlice(field.Type(), 0, len(list))
+		for _, impl := range list {
+			instance, err := t.runtimeBean(impl)
+			if err != nil {
+				return err
 			}
+			newSlice = reflect.Append(newSlice, instance.valuePtr)
 		}
 		field.Set(newSlice)
 		return nil
@@ -322,19 +336,19 @@ func (t *injectionDef) inject(value *reflect.Value, deep []beanlist) error {
 
 	if t.table {
 
-		field.Set(reflect.MakeMap(field.Type()))
-
-		visited := make(map[string]bool)
-		for _, instance := range list {
-			if !instance.valuePtr.IsValid() {
-				if visited[instance.name] {
-					return errors.Errorf("can not inject duplicates '%s' to the map field '%s' in class '%v'", instance.name, t.fieldName, t.class)
-				}
-				visited[instance.name] = true
-				field.SetMapIndex(reflect.ValueOf(instance.name), instance.valuePtr)
+		newMap := reflect.MakeMapWithSize(field.Type(), len(list))
+		for _, impl := range list {
+			instance, err := t.runtimeBean(impl)
+			if err != nil {
+				return err
+			}
+			key := reflect.ValueOf(instance.name)
+			if newMap.MapIndex(key).IsValid() {
+				return errors.Errorf("can not inject duplicates '%s' to the map field '%s' in class '%v'", instance.name, t.fieldName, t.class)
 			}
+			newMap.SetMapIndex(key, instance.valuePtr)
 		}
-
+		field.Set(newMap)
 		return nil
 	}
 
@@ -342,25 +356,53 @@ func (t *injectionDef) inject(value *reflect.Value, deep []beanlist) error {
 		return errors.Errorf("field '%s' in class '%v' can not be injected with multiple candidates %+v", t.fieldName, t.class, list)
 	}
 
-	impl := list[0]
-
-	if impl.lifecycle != BeanInitialized {
-		return errors.Errorf("field '%s' in class '%v' can not be injected with non-initialized bean %+v", t.fieldName, t.class, impl)
+	impl, err := t.runtimeBean(list[0])
+	if err != nil {
+		return err
 	}
 
+	field.Set(impl.valuePtr)
+
+	return nil
+}
+
+/**
+Gets the bean for runtime injection, lazy field accepts non-initialized bean
+*/
+func (t *injectionDef) runtimeBean(impl *bean) (*bean, error) {
+
 	if impl.beenFactory != nil {
 
-		service, _, err := impl.beenFactory.ctor()
+		if impl.beenFactory.bean.lifecycle != BeanInitialized {
+			return nil, errors.Errorf("field '%s' in class '%v' can not be injected with non-initialized factory bean %+v", t.fieldName, t.class, impl.beenFactory.bean)
+		}
+
+		service, err := impl.beenFactory.runtimeBean(t.injectionPoint())
 		if err != nil {
-			return errors.Errorf("field '%s' in class '%v' can not be injected because of factory bean %+v error, %v", t.fieldName, t.class, impl, err)
+			return nil, errors.Errorf("field '%s' in class '%v' can not be injected because of factory bean %+v error, %v", t.fieldName, t.class, impl, err)
 		}
 
-		impl = service
+		return service, nil
 	}
 
-	field.Set(impl.valuePtr)
+	if !t.lazy && impl.lifecycle != BeanInitialized {
+		return nil, errors.Errorf("field '%s' in class '%v' can not be injected with non-initialized bean %+v", t.fieldName, t.class, impl)
+	}
 
-	return nil
+	return impl, nil
+}
+
+/**
+Describes the field for the factory bean that produces an object for it
+*/
+func (t *injectionDef) injectionPoint() *InjectionPoint {
+	return &InjectionPoint{
+		Class:     reflect.PtrTo(t.class),
+		FieldName: t.fieldName,
+		FieldType: t.class.Field(t.fieldNum).Type,
+		Tag:       t.tag,
+		Qualifier: t.qualifier,
+	}
 }
 
 func (t *injectionDef) filterBeans(list []*bean) []*bean {
@@ -377,6 +419,24 @@ func (t *injectionDef) filterBeans(list []*bean) []*bean {
 	}
 }
 
+/**
+Non-singleton factory registers each produced bean, keep only one candidate per factory to produce a new one
+*/
+func distinctFactoryBeans(list []*bean) []*bean {
+	var candidates []*bean
+	visited := make(map[*factory]bool)
+	for _, b := range list {
+		if b.beenFactory != nil {
+			if visited[b.beenFactory] {
+				continue
+			}
+			visited[b.beenFactory] = true
+		}
+		candidates = append(candidates, b)
+	}
+	return candidates
+}
+
 /**
 User friendly information about class and field
 */
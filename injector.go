@@ -0,0 +1,153 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"reflect"
+)
+
+/**
+Compiled runtime injection of the type, valid while beans of the context and its parents are not changed
+*/
+type runtimeInjector struct {
+
+	/**
+	Definition of the injected type
+	*/
+	beanDef *beanDef
+
+	/**
+	Sum of generations of the context and parents at compilation
+	*/
+	generation uint64
+
+	/**
+	Injected fields in the order of the definition
+	*/
+	fields []runtimeField
+}
+
+/**
+Injected field with the target resolved on compilation
+*/
+type runtimeField struct {
+	injectionDef *injectionDef
+
+	/**
+	Index of the field in the struct
+	*/
+	fieldNum int
+
+	/**
+	Value of the field type holding the singleton bean, invalid if the field is resolved on each injection
+	*/
+	target reflect.Value
+
+	/**
+	Optional field without candidates
+	*/
+	skip bool
+}
+
+/**
+Marks the change of beans in the context, compiled runtime injectors of the context and its children are recompiled on the next Inject
+*/
+func (t *context) changed() {
+	t.generation.Add(1)
+}
+
+/**
+Returns the sum of generations of the context and parents, it grows on every change of them
+*/
+func (t *context) generations() uint64 {
+	var sum uint64
+	for ctx := t; ctx != nil; ctx = ctx.parent {
+		sum += ctx.generation.Load()
+	}
+	return sum
+}
+
+/**
+Returns the compiled injector of the type from runtime cache, compiles it if not exist or the context was changed
+*/
+func (t *context) injector(obj interface{}, classPtr reflect.Type) (*runtimeInjector, error) {
+	generation := t.generations()
+	var def *beanDef
+	if cached, ok := t.runtimeCache.Load(classPtr); ok {
+		injector := cached.(*runtimeInjector)
+		if injector.generation == generation {
+			return injector, nil
+		}
+		def = injector.beanDef
+	} else {
+		b, err := investigate(obj, classPtr)
+		if err != nil {
+			return nil, err
+		}
+		def = b.beanDef
+	}
+	injector := t.compileInjector(def, generation)
+	t.runtimeCache.Store(classPtr, injector)
+	return injector, nil
+}
+
+/**
+Resolves targets of fields holding a single initialized bean, collections and beans produced by factories are resolved on each injection
+*/
+func (t *context) compileInjector(def *beanDef, generation uint64) *runtimeInjector {
+
+	injector := &runtimeInjector{
+		beanDef:    def,
+		generation: generation,
+		fields:     make([]runtimeField, len(def.fields)),
+	}
+
+	class := def.classPtr.Elem()
+	for i, inject := range def.fields {
+
+		field := &injector.fields[i]
+		field.injectionDef = inject
+		field.fieldNum = inject.fieldNum
+
+		if inject.slice || inject.table || !class.Field(inject.fieldNum).IsExported() {
+			continue
+		}
+
+		var list []*bean
+		if deep := t.getBean(inject.fieldType); len(deep) > 0 {
+			list = inject.filterBeans(orderBeans(levelBeans(deep, inject.level)))
+		}
+
+		switch {
+		case len(list) == 0 && inject.optional:
+			field.skip = true
+		case len(list) == 1 && list[0].beenFactory == nil && (inject.lazy || list[0].lifecycle == BeanInitialized):
+			field.target = reflect.New(inject.fieldType).Elem()
+			field.target.Set(list[0].valuePtr)
+		}
+	}
+
+	return injector
+}
+
+/**
+Injects fields of the object, resolved targets are set without search
+*/
+func (t *runtimeInjector) inject(ctx *context, valuePtr reflect.Value) error {
+	value := valuePtr.Elem()
+	for i := range t.fields {
+		field := &t.fields[i]
+		switch {
+		case field.skip:
+		case field.target.IsValid():
+			value.Field(field.fieldNum).Set(field.target)
+		default:
+			if err := field.injectionDef.inject(&value, ctx.getBean(field.injectionDef.fieldType)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
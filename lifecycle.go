@@ -0,0 +1,152 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	gocontext "context"
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+func (t *context) Start() (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("start context recovered with error %v", r)
+		}
+	}()
+
+	for _, ctx := range t.descendants() {
+		if err := ctx.startBeans(gocontext.Background()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *context) Stop() (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("stop context recovered with error %v", r)
+		}
+	}()
+
+	var listErr []error
+	contexts := t.descendants()
+	for j := len(contexts) - 1; j >= 0; j-- {
+		if e := contexts[j].stopBeans(gocontext.Background()); e != nil {
+			listErr = append(listErr, e)
+		}
+	}
+	return multipleErr(listErr)
+}
+
+/**
+Starts not running Lifecycle beans of the context, stops started ones on failure
+*/
+func (t *context) startBeans(ctx gocontext.Context) error {
+	var started []*bean
+	for _, b := range t.lifecycleBeans() {
+		if err := startBean(ctx, b); err != nil {
+			t.metrics.fail(PhaseLifecycle)
+			for j := len(started) - 1; j >= 0; j-- {
+				stopBean(ctx, started[j])
+			}
+			return err
+		}
+		started = append(started, b)
+	}
+	t.running.Store(true)
+	return nil
+}
+
+/**
+Stops running Lifecycle beans of the context in reverse order
+*/
+func (t *context) stopBeans(ctx gocontext.Context) error {
+	t.running.Store(false)
+	var listErr []error
+	list := t.lifecycleBeans()
+	for j := len(list) - 1; j >= 0; j-- {
+		if err := stopBean(ctx, list[j]); err != nil {
+			t.metrics.fail(PhaseLifecycle)
+			listErr = append(listErr, err)
+		}
+	}
+	return multipleErr(listErr)
+}
+
+func startBean(ctx gocontext.Context, b *bean) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("start bean '%s' recovered with error %v", b.name, r)
+		}
+	}()
+
+	l := b.obj.(Lifecycle)
+	if l.IsRunning() {
+		return nil
+	}
+	if Verbose {
+		fmt.Printf("Start bean '%s' with type '%v' in phase %d\n", b.name, b.beanDef.classPtr, beanPhase(b))
+	}
+	if err := l.Start(ctx); err != nil {
+		return errors.Errorf("start bean '%s' failed, %v", b.name, err)
+	}
+	return nil
+}
+
+func stopBean(ctx gocontext.Context, b *bean) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("stop bean '%s' recovered with error %v", b.name, r)
+		}
+	}()
+
+	l := b.obj.(Lifecycle)
+	if !l.IsRunning() {
+		return nil
+	}
+	if Verbose {
+		fmt.Printf("Stop bean '%s' with type '%v' in phase %d\n", b.name, b.beanDef.classPtr, beanPhase(b))
+	}
+	if err := l.Stop(ctx); err != nil {
+		return errors.Errorf("stop bean '%s' failed, %v", b.name, err)
+	}
+	return nil
+}
+
+/**
+Returns initialized Lifecycle beans of the context ordered by phase and initialization order
+*/
+func (t *context) lifecycleBeans() []*bean {
+	var list []*bean
+	for _, b := range initOrder(t.coreBeans()) {
+		if _, ok := b.obj.(Lifecycle); ok && b.lifecycle == BeanInitialized {
+			list = append(list, b)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return beanPhase(list[i]) < beanPhase(list[j])
+	})
+	return list
+}
+
+func beanPhase(b *bean) int {
+	if phased, ok := b.obj.(PhasedBean); ok {
+		return phased.Phase()
+	}
+	return 0
+}
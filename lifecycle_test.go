@@ -0,0 +1,227 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"testing"
+)
+
+type phasedComponent struct {
+	Journal *lifecycleJournal `inject`
+	name    string
+	phase   int
+	fail    bool
+	running bool
+}
+
+func (t *phasedComponent) BeanName() string {
+	return t.name
+}
+
+func (t *phasedComponent) Phase() int {
+	return t.phase
+}
+
+func (t *phasedComponent) Start(ctx context.Context) error {
+	if t.fail {
+		return errors.New("start failed")
+	}
+	t.Journal.records = append(t.Journal.records, "start:"+t.name)
+	t.running = true
+	return nil
+}
+
+func (t *phasedComponent) Stop(ctx context.Context) error {
+	t.Journal.records = append(t.Journal.records, "stop:"+t.name)
+	t.running = false
+	return nil
+}
+
+func (t *phasedComponent) IsRunning() bool {
+	return t.running
+}
+
+func (t *phasedComponent) Destroy() error {
+	t.Journal.records = append(t.Journal.records, "destroy:"+t.name)
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+
+	beans.Verbose = true
+
+	journal := &lifecycleJournal{}
+	ctx, err := beans.Create(
+		journal,
+		&phasedComponent{name: "server", phase: 10},
+		&phasedComponent{name: "pool", phase: -1},
+		&phasedComponent{name: "cache"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"start:pool", "start:cache", "start:server"}, journal.records)
+
+	journal.records = nil
+	require.NoError(t, ctx.Stop())
+	require.Equal(t, []string{"stop:server", "stop:cache", "stop:pool"}, journal.records)
+
+	// stopped beans are not stopped again
+	journal.records = nil
+	require.NoError(t, ctx.Stop())
+	require.Equal(t, 0, len(journal.records))
+
+	require.NoError(t, ctx.Start())
+	require.Equal(t, []string{"start:pool", "start:cache", "start:server"}, journal.records)
+
+	journal.records = nil
+	child, err := ctx.Extend(&phasedComponent{name: "child"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"start:child"}, journal.records)
+
+	journal.records = nil
+	require.NoError(t, ctx.Stop())
+	require.Equal(t, []string{"stop:child", "stop:server", "stop:cache", "stop:pool"}, journal.records)
+
+	journal.records = nil
+	require.NoError(t, ctx.Start())
+	require.NoError(t, child.Close())
+	require.Equal(t, []string{"start:pool", "start:cache", "start:server", "start:child", "stop:child", "destroy:child"}, journal.records)
+
+	journal.records = nil
+	require.NoError(t, ctx.Close())
+	require.Equal(t, []string{"stop:server", "stop:cache", "stop:pool"}, journal.records[:3])
+	require.Equal(t, 6, len(journal.records))
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+
+	journal := &lifecycleJournal{}
+	_, err := beans.Create(
+		journal,
+		&phasedComponent{name: "pool", phase: -1},
+		&phasedComponent{name: "server", phase: 10, fail: true},
+	)
+	require.Error(t, err)
+	require.Equal(t, 4, len(journal.records))
+	require.Equal(t, []string{"start:pool", "stop:pool"}, journal.records[:2])
+	require.ElementsMatch(t, []string{"destroy:server", "destroy:pool"}, journal.records[2:])
+}
+
+func TestRegisterStartFailure(t *testing.T) {
+
+	journal := &lifecycleJournal{}
+	ctx, err := beans.Create(journal)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	err = ctx.Register(
+		&phasedComponent{name: "pool", phase: -1},
+		&phasedComponent{name: "server", phase: 10, fail: true},
+	)
+	require.Error(t, err)
+	require.Equal(t, 4, len(journal.records))
+	require.Equal(t, []string{"start:pool", "stop:pool"}, journal.records[:2])
+	require.ElementsMatch(t, []string{"destroy:server", "destroy:pool"}, journal.records[2:])
+
+	// beans of the failed registration are removed from the context
+	require.Equal(t, 0, len(ctx.Bean(reflect.TypeOf((*phasedComponent)(nil)), beans.DefaultLevel)))
+	require.Equal(t, 0, len(ctx.Lookup("pool", beans.DefaultLevel)))
+
+	journal.records = nil
+	require.NoError(t, ctx.Register(&phasedComponent{name: "cache"}))
+	require.Equal(t, []string{"start:cache"}, journal.records)
+}
+
+type closingStorage struct {
+	open bool
+}
+
+func (t *closingStorage) PostConstruct() error {
+	t.open = true
+	return nil
+}
+
+func (t *closingStorage) Destroy() error {
+	t.open = false
+	return nil
+}
+
+func TestCloseDuringReload(t *testing.T) {
+
+	storage := &closingStorage{}
+	ctx, err := beans.Create(storage)
+	require.NoError(t, err)
+
+	list := ctx.Bean(reflect.TypeOf(storage), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			ctx.Reload(list[0])
+			if i == 0 {
+				close(started)
+			}
+		}
+	}()
+
+	<-started
+
+	// Close waits for the reload in progress and reloads wait for Close
+	require.NoError(t, ctx.Close())
+	<-done
+}
+
+type StartStopServer interface {
+	Start() error
+	Stop() error
+}
+
+type startStopServer struct {
+}
+
+func (t *startStopServer) Start() error {
+	return nil
+}
+
+func (t *startStopServer) Stop() error {
+	return nil
+}
+
+type startStopHolder struct {
+	Server    StartStopServer      `inject`
+	Ctx       beans.Context        `inject`
+	Publisher beans.EventPublisher `inject`
+}
+
+func TestContextNotCandidateOfUserInterface(t *testing.T) {
+
+	// the context has methods Start and Stop too, but it is injected only as Context or EventPublisher
+	server := &startStopServer{}
+	holder := &startStopHolder{}
+	ctx, err := beans.Create(server, holder)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.True(t, holder.Server == server)
+	require.True(t, holder.Ctx == ctx)
+	require.True(t, holder.Publisher == ctx)
+
+	list := ctx.Bean(reflect.TypeOf((*StartStopServer)(nil)).Elem(), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	// the same types compiled by the blueprint
+	bp, err := beans.Compile(&startStopServer{}, &startStopHolder{})
+	require.NoError(t, err)
+	compiled, err := bp.New()
+	require.NoError(t, err)
+	defer compiled.Close()
+}
@@ -0,0 +1,182 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+Phases of the context where failures are counted
+*/
+const (
+	PhaseConstruct = "construct"
+	PhaseDestroy   = "destroy"
+	PhaseReload    = "reload"
+	PhaseInject    = "inject"
+	PhaseLifecycle = "lifecycle"
+	PhaseRefresh   = "refresh"
+	PhaseService   = "service"
+)
+
+var failurePhases = []string{PhaseConstruct, PhaseDestroy, PhaseReload, PhaseInject, PhaseLifecycle, PhaseRefresh, PhaseService}
+
+/**
+Upper bounds in seconds of histogram buckets for durations of PostConstruct, factory Object and Destroy calls, never changed
+*/
+var durationBuckets = []float64{0.0001, 0.001, 0.01, 0.1, 1, 10}
+
+/**
+Snapshot of the context metrics
+*/
+type Metrics struct {
+	/**
+	Number of beans in the context, including the context itself
+	*/
+	Beans int
+	/**
+	Durations of PostConstruct and factory Object calls
+	*/
+	Construct Histogram
+	/**
+	Durations of Destroy calls
+	*/
+	Destroy Histogram
+	/**
+	Number of failures by phase
+	*/
+	Failures map[string]uint64
+	/**
+	Number of successful Reload, ReloadCascade, ReloadFactory and Replace calls
+	*/
+	Reloads uint64
+	/**
+	Number of runtime Inject calls
+	*/
+	Injects uint64
+	/**
+	Number of bean lookups by type found or not found in the registry, fields resolved by compiled runtime injectors are not looked up and not counted
+	*/
+	CacheHits   uint64
+	CacheMisses uint64
+}
+
+/**
+Snapshot of the histogram, counts are cumulative for buckets
+*/
+type Histogram struct {
+	Buckets []float64
+	Counts  []uint64
+	Sum     float64
+	Count   uint64
+}
+
+type histogram struct {
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (t *histogram) observe(d time.Duration) {
+	seconds := d.Seconds()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.counts == nil {
+		t.counts = make([]uint64, len(durationBuckets))
+	}
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			t.counts[i]++
+		}
+	}
+	t.sum += seconds
+	t.count++
+}
+
+func (t *histogram) snapshot() Histogram {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := make([]uint64, len(durationBuckets))
+	copy(counts, t.counts)
+	return Histogram{
+		Buckets: append([]float64(nil), durationBuckets...),
+		Counts:  counts,
+		Sum:     t.sum,
+		Count:   t.count,
+	}
+}
+
+/**
+Metrics collected by the context
+*/
+type metrics struct {
+	construct histogram
+	destroy   histogram
+
+	failuresMu sync.Mutex
+	failures   map[string]uint64
+
+	reloads     atomic.Uint64
+	injects     atomic.Uint64
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+}
+
+func (t *metrics) fail(phase string) {
+	t.failuresMu.Lock()
+	defer t.failuresMu.Unlock()
+	if t.failures == nil {
+		t.failures = make(map[string]uint64)
+	}
+	t.failures[phase]++
+}
+
+/**
+Records the duration of PostConstruct or factory Object call that created the bean
+*/
+func (t *context) observeConstruct(b *bean, start time.Time) {
+	b.initDuration = time.Since(start)
+	t.metrics.construct.observe(b.initDuration)
+}
+
+func (t *context) Metrics() Metrics {
+	m := Metrics{
+		Beans:       len(t.uniqueBeans()),
+		Construct:   t.metrics.construct.snapshot(),
+		Destroy:     t.metrics.destroy.snapshot(),
+		Failures:    make(map[string]uint64, len(failurePhases)),
+		Reloads:     t.metrics.reloads.Load(),
+		Injects:     t.metrics.injects.Load(),
+		CacheHits:   t.metrics.cacheHits.Load(),
+		CacheMisses: t.metrics.cacheMisses.Load(),
+	}
+	for _, phase := range failurePhases {
+		m.Failures[phase] = 0
+	}
+	t.metrics.failuresMu.Lock()
+	for phase, n := range t.metrics.failures {
+		m.Failures[phase] = n
+	}
+	t.metrics.failuresMu.Unlock()
+	return m
+}
+
+/**
+Returns beans of the context without duplicates of beans registered by multiple types
+*/
+func (t *context) uniqueBeans() []*bean {
+	var list []*bean
+	visited := make(map[*bean]bool)
+	for _, b := range t.coreBeans() {
+		if !visited[b] {
+			visited[b] = true
+			list = append(list, b)
+		}
+	}
+	return list
+}
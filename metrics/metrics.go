@@ -0,0 +1,129 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+/**
+Package metrics renders metrics of the context and its parents in the Prometheus text exposition format.
+Metrics of every context have the label 'level', 0 is the context of the handler, 1 is the parent and so on.
+
+Example:
+	http.Handle("/metrics", metrics.Handler(ctx))
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"go.arpabet.com/beans"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+/**
+Creates the handler that writes metrics of the context and its parents
+*/
+func Handler(ctx beans.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		Write(w, ctx)
+	})
+}
+
+type levelMetrics struct {
+	level   string
+	metrics beans.Metrics
+}
+
+/**
+Writes metrics of the context and its parents in the Prometheus text exposition format
+*/
+func Write(w io.Writer, ctx beans.Context) error {
+
+	var list []levelMetrics
+	level := 0
+	for c, ok := ctx, true; ok; c, ok = c.Parent() {
+		list = append(list, levelMetrics{level: strconv.Itoa(level), metrics: c.Metrics()})
+		level++
+	}
+
+	out := bufio.NewWriter(w)
+
+	writeHeader(out, "beans_count", "gauge", "Number of beans in the context.")
+	for _, lm := range list {
+		writeSample(out, "beans_count", lm.level, "", "", float64(lm.metrics.Beans))
+	}
+
+	writeHistogram(out, "beans_construct_duration_seconds", "Duration of PostConstruct and factory Object calls.", list, func(m beans.Metrics) beans.Histogram {
+		return m.Construct
+	})
+	writeHistogram(out, "beans_destroy_duration_seconds", "Duration of Destroy calls.", list, func(m beans.Metrics) beans.Histogram {
+		return m.Destroy
+	})
+
+	writeHeader(out, "beans_failures_total", "counter", "Number of failures by phase.")
+	for _, lm := range list {
+		phases := make([]string, 0, len(lm.metrics.Failures))
+		for phase := range lm.metrics.Failures {
+			phases = append(phases, phase)
+		}
+		sort.Strings(phases)
+		for _, phase := range phases {
+			writeSample(out, "beans_failures_total", lm.level, "phase", phase, float64(lm.metrics.Failures[phase]))
+		}
+	}
+
+	writeCounter(out, "beans_reloads_total", "Number of successful bean reloads and replacements.", list, func(m beans.Metrics) uint64 {
+		return m.Reloads
+	})
+	writeCounter(out, "beans_inject_calls_total", "Number of runtime Inject calls.", list, func(m beans.Metrics) uint64 {
+		return m.Injects
+	})
+	writeCounter(out, "beans_registry_cache_hits_total", "Number of bean lookups by type found in the registry.", list, func(m beans.Metrics) uint64 {
+		return m.CacheHits
+	})
+	writeCounter(out, "beans_registry_cache_misses_total", "Number of bean lookups by type not found in the registry.", list, func(m beans.Metrics) uint64 {
+		return m.CacheMisses
+	})
+
+	return out.Flush()
+}
+
+func writeHeader(out *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeSample(out *bufio.Writer, name, level, label, value string, sample float64) {
+	if label != "" {
+		fmt.Fprintf(out, "%s{level=\"%s\",%s=\"%s\"} %s\n", name, level, label, value, formatFloat(sample))
+	} else {
+		fmt.Fprintf(out, "%s{level=\"%s\"} %s\n", name, level, formatFloat(sample))
+	}
+}
+
+func writeCounter(out *bufio.Writer, name, help string, list []levelMetrics, value func(beans.Metrics) uint64) {
+	writeHeader(out, name, "counter", help)
+	for _, lm := range list {
+		writeSample(out, name, lm.level, "", "", float64(value(lm.metrics)))
+	}
+}
+
+func writeHistogram(out *bufio.Writer, name, help string, list []levelMetrics, value func(beans.Metrics) beans.Histogram) {
+	writeHeader(out, name, "histogram", help)
+	for _, lm := range list {
+		h := value(lm.metrics)
+		for i, bound := range h.Buckets {
+			writeSample(out, name+"_bucket", lm.level, "le", formatFloat(bound), float64(h.Counts[i]))
+		}
+		writeSample(out, name+"_bucket", lm.level, "le", "+Inf", float64(h.Count))
+		writeSample(out, name+"_sum", lm.level, "", "", h.Sum)
+		writeSample(out, name+"_count", lm.level, "", "", float64(h.Count))
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,65 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package metrics_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"go.arpabet.com/beans/metrics"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type storage struct {
+}
+
+func (t *storage) PostConstruct() error {
+	return nil
+}
+
+type handler struct {
+	Storage *storage `inject`
+}
+
+type otherHandler struct {
+	Storage *storage `inject`
+}
+
+func TestHandler(t *testing.T) {
+
+	parent, err := beans.Create(&storage{})
+	require.NoError(t, err)
+	defer parent.Close()
+
+	ctx, err := parent.Extend()
+	require.NoError(t, err)
+	require.NoError(t, ctx.Inject(&handler{}))
+	// the compiled injector of handler does not look up the storage again, other type finds it in the registry
+	require.NoError(t, ctx.Inject(&handler{}))
+	require.NoError(t, ctx.Inject(&otherHandler{}))
+
+	w := httptest.NewRecorder()
+	metrics.Handler(ctx).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(body, "\n") {
+		lines[line] = true
+	}
+
+	require.True(t, lines["# TYPE beans_count gauge"], body)
+	require.True(t, lines[`beans_count{level="0"} 1`], body)
+	require.True(t, lines[`beans_count{level="1"} 2`], body)
+	require.True(t, lines["# TYPE beans_construct_duration_seconds histogram"], body)
+	require.True(t, lines[`beans_construct_duration_seconds_bucket{level="1",le="+Inf"} 1`], body)
+	require.True(t, lines[`beans_construct_duration_seconds_count{level="1"} 1`], body)
+	require.True(t, lines[`beans_failures_total{level="0",phase="inject"} 0`], body)
+	require.True(t, lines[`beans_inject_calls_total{level="0"} 3`], body)
+	require.True(t, lines[`beans_inject_calls_total{level="1"} 0`], body)
+	require.True(t, lines[`beans_registry_cache_misses_total{level="0"} 1`], body)
+	require.True(t, lines[`beans_registry_cache_hits_total{level="0"} 1`], body)
+}
@@ -0,0 +1,90 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"testing"
+)
+
+type meteredStorage struct {
+	failDestroy bool
+}
+
+func (t *meteredStorage) PostConstruct() error {
+	return nil
+}
+
+func (t *meteredStorage) Destroy() error {
+	if t.failDestroy {
+		return errors.New("destroy failed")
+	}
+	return nil
+}
+
+type meteredRequest struct {
+	Storage *meteredStorage `inject`
+}
+
+type meteredOtherRequest struct {
+	Storage *meteredStorage `inject`
+}
+
+func TestMetrics(t *testing.T) {
+
+	storage := &meteredStorage{}
+	ctx, err := beans.Create(storage)
+	require.NoError(t, err)
+
+	m := ctx.Metrics()
+	require.Equal(t, 2, m.Beans)
+	require.Equal(t, uint64(1), m.Construct.Count)
+	require.Equal(t, len(m.Construct.Buckets), len(m.Construct.Counts))
+	require.Equal(t, uint64(0), m.Injects)
+
+	require.NoError(t, ctx.Inject(&meteredRequest{}))
+	require.NoError(t, ctx.Inject(&meteredRequest{}))
+	require.Error(t, ctx.Inject(meteredRequest{}))
+
+	// compiled injectors do not look up beans, the lookup of other type hits the registry
+	hits := ctx.Metrics().CacheHits
+	require.NoError(t, ctx.Inject(&meteredRequest{}))
+	require.Equal(t, hits, ctx.Metrics().CacheHits)
+	require.NoError(t, ctx.Inject(&meteredOtherRequest{}))
+	require.Equal(t, hits+1, ctx.Metrics().CacheHits)
+
+	list := ctx.Lookup("*beans_test.meteredStorage", 0)
+	require.Equal(t, 1, len(list))
+	require.NoError(t, ctx.ReloadCascade(list[0]))
+
+	m = ctx.Metrics()
+	require.Equal(t, uint64(5), m.Injects)
+	require.Equal(t, uint64(1), m.Failures[beans.PhaseInject])
+	require.Equal(t, uint64(0), m.Failures[beans.PhaseConstruct])
+	require.Equal(t, uint64(1), m.Reloads)
+	require.Equal(t, uint64(2), m.Construct.Count)
+	require.Equal(t, uint64(1), m.Destroy.Count)
+	require.True(t, m.CacheHits > 0)
+
+	// reload of the bean itself is counted like the reload by the context
+	require.NoError(t, list[0].Reload())
+	m = ctx.Metrics()
+	require.Equal(t, uint64(2), m.Reloads)
+	require.Equal(t, uint64(3), m.Construct.Count)
+
+	// buckets of the snapshot are a copy
+	m.Construct.Buckets[0] = 100
+	require.NotEqual(t, float64(100), ctx.Metrics().Construct.Buckets[0])
+
+	storage.failDestroy = true
+	// destroy errors are not returned by Close, only counted
+	require.NoError(t, ctx.Close())
+
+	m = ctx.Metrics()
+	require.Equal(t, uint64(3), m.Destroy.Count)
+	require.Equal(t, uint64(1), m.Failures[beans.PhaseDestroy])
+}
@@ -0,0 +1,104 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"testing"
+)
+
+var dataSourceClass = reflect.TypeOf((*dataSource)(nil))
+
+type dataSource struct {
+	url         string
+	order       int
+	constructed bool
+	destroyed   bool
+}
+
+func (t *dataSource) BeanOrder() int {
+	return t.order
+}
+
+func (t *dataSource) PostConstruct() error {
+	t.constructed = true
+	return nil
+}
+
+func (t *dataSource) Destroy() error {
+	t.destroyed = true
+	return nil
+}
+
+type dataSourceFactory struct {
+	urls        map[string]string
+	constructed bool
+}
+
+func (t *dataSourceFactory) Objects() (map[string]interface{}, error) {
+	objects := make(map[string]interface{})
+	order := 0
+	for name, url := range t.urls {
+		objects[name] = &dataSource{url: url, order: len(t.urls) - order}
+		order++
+	}
+	objects["urls"] = func() map[string]string { return t.urls }
+	return objects, nil
+}
+
+func (t *dataSourceFactory) PostConstruct() error {
+	t.constructed = true
+	return nil
+}
+
+type dataSourceConsumer struct {
+	Primary *dataSource              `inject:"bean=primary"`
+	All     []*dataSource            `inject`
+	ByName  map[string]*dataSource   `inject`
+	Urls    func() map[string]string `inject`
+	Factory *dataSourceFactory       `inject`
+	testing *testing.T
+}
+
+func (t *dataSourceConsumer) PostConstruct() error {
+	require.True(t.testing, t.Factory.constructed)
+	require.True(t.testing, t.Primary.constructed)
+	return nil
+}
+
+func TestMultiFactoryBean(t *testing.T) {
+
+	beans.Verbose = true
+
+	consumer := &dataSourceConsumer{testing: t}
+	ctx, err := beans.Create(
+		&dataSourceFactory{urls: map[string]string{
+			"primary": "db://primary",
+			"replica": "db://replica",
+		}},
+		consumer,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, "db://primary", consumer.Primary.url)
+	require.Equal(t, 2, len(consumer.All))
+	require.True(t, consumer.All[0].order < consumer.All[1].order)
+	require.Equal(t, 2, len(consumer.ByName))
+	require.Equal(t, "db://replica", consumer.ByName["replica"].url)
+	require.Equal(t, 2, len(consumer.Urls()))
+
+	list := ctx.Lookup("replica", beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	require.Equal(t, dataSourceClass, list[0].Class())
+	require.Equal(t, beans.BeanInitialized, list[0].Lifecycle())
+
+	err = ctx.Close()
+	require.NoError(t, err)
+
+	require.True(t, consumer.ByName["primary"].destroyed)
+	require.True(t, consumer.ByName["replica"].destroyed)
+}
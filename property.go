@@ -0,0 +1,456 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var durationClass = reflect.TypeOf(time.Duration(0))
+
+type propertyDef struct {
+
+	/**
+	Field number of that struct
+	*/
+	fieldNum int
+	/**
+	Field name where property is going to be bound
+	*/
+	fieldName string
+	/**
+	Type of the field
+	*/
+	fieldType reflect.Type
+	/**
+	Key of the property
+	*/
+	key string
+	/**
+	Default value of the property if it is not defined in sources
+	*/
+	defaultValue string
+	hasDefault   bool
+}
+
+func (t *propertyDef) String() string {
+	return fmt.Sprintf("PropertyDef{%s,field=%s}", t.key, t.fieldName)
+}
+
+/**
+Parses 'value' tag in format 'key' or 'key,default=value', where default value could contain commas
+*/
+func parsePropertyDef(field reflect.StructField, j int, tag string, classPtr reflect.Type) (*propertyDef, error) {
+
+	if field.Anonymous || field.PkgPath != "" {
+		return nil, errors.Errorf("property can not be bound to anonymous or not public field '%s' in '%v'", field.Name, classPtr)
+	}
+
+	key := tag
+	var opts string
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		key, opts = tag[:i], tag[i+1:]
+	}
+
+	def := &propertyDef{
+		fieldNum:  j,
+		fieldName: field.Name,
+		fieldType: field.Type,
+		key:       strings.TrimSpace(key),
+	}
+
+	if def.key == "" {
+		return nil, errors.Errorf("empty property key in 'value' tag of field '%s' on position %d in %v", field.Name, j, classPtr)
+	}
+
+	if opts = strings.TrimSpace(opts); opts != "" {
+		if !strings.HasPrefix(opts, "default=") {
+			return nil, errors.Errorf("unknown option '%s' in 'value' tag of field '%s' on position %d in %v", opts, field.Name, j, classPtr)
+		}
+		def.defaultValue = strings.TrimPrefix(opts, "default=")
+		def.hasDefault = true
+	}
+
+	if !isPropertyType(field.Type) {
+		return nil, errors.Errorf("not supported property field type '%v' on position %d in %v with 'value' tag", field.Type, j, classPtr)
+	}
+
+	return def, nil
+}
+
+func isPropertyType(typ reflect.Type) bool {
+	if typ == durationClass {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Slice && isPropertyType(typ.Elem())
+	default:
+		return false
+	}
+}
+
+/**
+Converts the property value to the field type, slices are comma separated
+*/
+func convertProperty(s string, typ reflect.Type) (reflect.Value, error) {
+
+	if typ == durationClass {
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(d), nil
+	}
+
+	value := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 0, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(s), 0, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if strings.TrimSpace(s) == "" {
+			return reflect.MakeSlice(typ, 0, 0), nil
+		}
+		parts := strings.Split(s, ",")
+		value = reflect.MakeSlice(typ, 0, len(parts))
+		for _, part := range parts {
+			el, err := convertProperty(strings.TrimSpace(part), typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value = reflect.Append(value, el)
+		}
+	default:
+		return reflect.Value{}, errors.Errorf("not supported property type '%v'", typ)
+	}
+	return value, nil
+}
+
+/**
+Properties of the context loaded from property sources
+*/
+type properties struct {
+
+	/**
+	Property sources of the context in order
+	*/
+	sources []*bean
+
+	/**
+	Guards values and listeners
+	*/
+	mu sync.RWMutex
+
+	/**
+	Merged properties of sources in the context
+	*/
+	values map[string]string
+
+	/**
+	Keys of properties provided by secret sources
+	*/
+	secrets map[string]bool
+
+	/**
+	Listeners of property changes by key
+	*/
+	listeners map[string][]*propertyListener
+}
+
+type propertyListener struct {
+	fn PropertyListener
+}
+
+func (t *properties) get(key string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	value, ok := t.values[key]
+	return value, ok
+}
+
+func (t *properties) set(values map[string]string, secrets map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.values = values
+	t.secrets = secrets
+}
+
+func (t *properties) isSecret(key string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.secrets[key]
+}
+
+/**
+Reads all sources in order, properties of the next source override properties of previous one.
+Property stays secret if any source marked it as secret.
+*/
+func readSources(sources []*bean) (map[string]string, map[string]bool, error) {
+	values := make(map[string]string)
+	secrets := make(map[string]bool)
+	for _, source := range sources {
+		props, err := source.obj.(PropertySource).Properties()
+		if err != nil {
+			return nil, nil, errors.Errorf("property source '%s' failed, %v", source.name, err)
+		}
+		if Verbose {
+			fmt.Printf("Property source '%s' has %d properties\n", source.name, len(props))
+		}
+		secretSource, hasSecrets := source.obj.(SecretPropertySource)
+		for key, value := range props {
+			values[key] = value
+			if hasSecrets && secretSource.IsSecret(key) {
+				secrets[key] = true
+			}
+		}
+	}
+	return values, secrets, nil
+}
+
+func (t *context) Property(key string) (string, bool) {
+	for ctx := t; ctx != nil; ctx = ctx.parent {
+		if value, ok := ctx.properties.get(key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func (t *context) Properties() map[string]string {
+	values := t.allProperties()
+	for key := range values {
+		if t.isSecret(key) {
+			values[key] = redacted
+		}
+	}
+	return values
+}
+
+/**
+Checks if the property is secret in this or parent contexts
+*/
+func (t *context) isSecret(key string) bool {
+	for ctx := t; ctx != nil; ctx = ctx.parent {
+		if ctx.properties.isSecret(key) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+Returns properties of this context merged with properties of parent contexts
+*/
+func (t *context) allProperties() map[string]string {
+	var values map[string]string
+	if t.parent != nil {
+		values = t.parent.allProperties()
+	} else {
+		values = make(map[string]string)
+	}
+	t.properties.mu.RLock()
+	defer t.properties.mu.RUnlock()
+	for key, value := range t.properties.values {
+		values[key] = value
+	}
+	return values
+}
+
+/**
+Binds fields with 'value' tag and fields of config struct of the object from properties of the context
+*/
+func (t *context) bindProperties(valuePtr reflect.Value, bd *beanDef) error {
+	if len(bd.properties) == 0 && bd.config == nil {
+		return nil
+	}
+	value := valuePtr.Elem()
+	for _, def := range bd.properties {
+		s, ok := t.Property(def.key)
+		if !ok {
+			if !def.hasDefault {
+				return errors.Errorf("can not find property '%s' required by field '%s' in class '%v'", def.key, def.fieldName, bd.classPtr)
+			}
+			s = def.defaultValue
+		}
+		v, err := convertProperty(s, def.fieldType)
+		if err != nil {
+			if t.isSecret(def.key) {
+				err = errMalformedSecret
+			}
+			return errors.Errorf("property '%s' can not be bound to field '%s' in class '%v', %v", def.key, def.fieldName, bd.classPtr, err)
+		}
+		value.Field(def.fieldNum).Set(v)
+	}
+	if bd.config != nil {
+		if err := bindConfig(value, bd.config.prefix, t.allProperties(), t.isSecret); err != nil {
+			return errors.Errorf("bind config '%v' failed, %v", bd.classPtr, err)
+		}
+	}
+	return nil
+}
+
+/**
+Binds properties of all scanned beans
+*/
+func (t *context) bindDefinitions(defs *definitions) error {
+	for _, list := range defs.core {
+		for _, b := range list {
+			if b.obj == nil || b.beanDef == nil {
+				continue
+			}
+			if err := t.bindProperties(b.valuePtr, b.beanDef); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/**
+Returns sorted keys of properties that are different in two maps
+*/
+func changedKeys(prev, next map[string]string) []string {
+	var keys []string
+	for key, value := range next {
+		if old, ok := prev[key]; !ok || old != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+/**
+Creates the property source from the snapshot of the map, later changes of the map are not visible to the context.
+Implement beans.PropertySource with own synchronization to provide changing properties on refresh.
+*/
+func PropertyMap(values map[string]string) PropertySource {
+	snapshot := make(map[string]string, len(values))
+	for key, value := range values {
+		snapshot[key] = value
+	}
+	return &mapSource{values: snapshot}
+}
+
+type mapSource struct {
+	values map[string]string
+}
+
+func (t *mapSource) Properties() (map[string]string, error) {
+	values := make(map[string]string, len(t.values))
+	for key, value := range t.values {
+		values[key] = value
+	}
+	return values, nil
+}
+
+/**
+Creates the property source from the file in format 'key=value' or 'key: value' per line, lines started with '#' or '!' are comments.
+The file is parsed again on refresh only if modification time or size has changed.
+*/
+func PropertyFile(path string) PropertySource {
+	return &fileSource{path: path}
+}
+
+type fileSource struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	values  map[string]string
+}
+
+func (t *fileSource) Properties() (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.values == nil || !info.ModTime().Equal(t.modTime) || info.Size() != t.size {
+		values, err := readPropertyFile(t.path)
+		if err != nil {
+			return nil, err
+		}
+		t.values = values
+		t.modTime = info.ModTime()
+		t.size = info.Size()
+	}
+
+	values := make(map[string]string, len(t.values))
+	for key, value := range t.values {
+		values[key] = value
+	}
+	return values, nil
+}
+
+func readPropertyFile(path string) (map[string]string, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid property on line %d in file '%s'", n, path)
+		}
+		values[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	return values, scanner.Err()
+}
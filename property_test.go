@@ -0,0 +1,120 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type serverConfig struct {
+	Host    string         `value:"server.host,default=localhost"`
+	Port    int            `value:"server.port"`
+	Debug   bool           `value:"server.debug,default=false"`
+	Timeout time.Duration  `value:"server.timeout,default=5s"`
+	Ratio   float64        `value:"server.ratio,default=0.5"`
+	Tags    []string       `value:"server.tags,default=a,b"`
+	Runtime *pluginRuntime `inject`
+	port    int
+}
+
+func (t *serverConfig) PostConstruct() error {
+	t.port = t.Port
+	return nil
+}
+
+type overrideSource struct {
+	values map[string]string
+}
+
+func (t *overrideSource) Properties() (map[string]string, error) {
+	return t.values, nil
+}
+
+func TestPropertyValues(t *testing.T) {
+
+	beans.Verbose = true
+
+	config := &serverConfig{}
+	ctx, err := beans.Create(
+		beans.PropertyMap(map[string]string{
+			"server.port":    "8080",
+			"server.timeout": "1m",
+		}),
+		&overrideSource{values: map[string]string{"server.port": "9090"}},
+		&pluginRuntime{},
+		config,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, "localhost", config.Host)
+	require.Equal(t, 9090, config.Port)
+	require.Equal(t, 9090, config.port)
+	require.False(t, config.Debug)
+	require.Equal(t, time.Minute, config.Timeout)
+	require.Equal(t, 0.5, config.Ratio)
+	require.Equal(t, []string{"a", "b"}, config.Tags)
+	require.NotNil(t, config.Runtime)
+
+	value, ok := ctx.Property("server.timeout")
+	require.True(t, ok)
+	require.Equal(t, "1m", value)
+
+	child, err := ctx.Extend(beans.PropertyMap(map[string]string{"server.host": "child"}))
+	require.NoError(t, err)
+	defer child.Close()
+
+	runtime := &struct {
+		Host string `value:"server.host"`
+		Port int    `value:"server.port"`
+	}{}
+	require.NoError(t, child.Inject(runtime))
+	require.Equal(t, "child", runtime.Host)
+	require.Equal(t, 9090, runtime.Port)
+}
+
+func TestPropertyErrors(t *testing.T) {
+
+	_, err := beans.Create(&pluginRuntime{}, &serverConfig{})
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "server.port"))
+
+	_, err = beans.Create(
+		beans.PropertyMap(map[string]string{"server.port": "http"}),
+		&pluginRuntime{},
+		&serverConfig{},
+	)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "Port"))
+
+	_, err = beans.Create(&struct {
+		Runtime *pluginRuntime `value:"runtime"`
+	}{})
+	require.Error(t, err)
+}
+
+func TestPropertyFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "application.properties")
+	err := os.WriteFile(path, []byte("# server\nserver.port = 7070\nserver.host: example.com\n"), 0644)
+	require.NoError(t, err)
+
+	config := &serverConfig{}
+	ctx, err := beans.Create(beans.PropertyFile(path), &pluginRuntime{}, config)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, 7070, config.Port)
+	require.Equal(t, "example.com", config.Host)
+
+	_, err = beans.Create(beans.PropertyFile(filepath.Join(t.TempDir(), "missing.properties")))
+	require.Error(t, err)
+}
@@ -0,0 +1,256 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func (t *context) Refresh() (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("refresh recovered with error %v", r)
+		}
+		if err != nil {
+			t.metrics.fail(PhaseRefresh)
+		}
+	}()
+
+	callbacks, listErr, err := t.rebind()
+	if err != nil {
+		return err
+	}
+
+	// callbacks run without the update lock, so listeners could reload beans or change the context
+	for _, callback := range callbacks {
+		if e := callback(); e != nil {
+			listErr = append(listErr, e)
+		}
+	}
+
+	return multipleErr(listErr)
+}
+
+/**
+Reads property sources and binds properties of refreshable beans under the update lock,
+returns OnRefresh calls, property listeners and events in order of contexts to call after the lock is released
+*/
+func (t *context) rebind() (callbacks []func() error, listErr []error, err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	contexts := t.descendants()
+
+	// read all sources first, so nothing is changed on failure
+	before := make([]map[string]string, len(contexts))
+	loaded := make([]map[string]string, len(contexts))
+	secrets := make([]map[string]bool, len(contexts))
+	for i, ctx := range contexts {
+		before[i] = ctx.allProperties()
+		values, secret, err := readSources(ctx.properties.sources)
+		if err != nil {
+			return nil, nil, err
+		}
+		loaded[i] = values
+		secrets[i] = secret
+	}
+
+	for i, ctx := range contexts {
+		ctx.properties.set(loaded[i], secrets[i])
+	}
+
+	for i, ctx := range contexts {
+		ctx := ctx
+		changed := changedKeys(before[i], ctx.allProperties())
+		if len(changed) == 0 {
+			continue
+		}
+		if Verbose {
+			fmt.Printf("Refresh: changed properties %v in context %v\n", changed, ctx)
+		}
+		for _, b := range initOrder(ctx.coreBeans()) {
+			b := b
+			refreshable, ok, e := ctx.refreshBean(b)
+			if e != nil {
+				listErr = append(listErr, e)
+				continue
+			}
+			if ok {
+				callbacks = append(callbacks, func() error {
+					if err := refreshable.OnRefresh(changed); err != nil {
+						return errors.Errorf("refresh bean '%s' failed on callback, %v", b.name, err)
+					}
+					return nil
+				})
+			}
+		}
+		callbacks = append(callbacks, func() error {
+			ctx.notifyListeners(changed)
+			return ctx.publishLocal(ContextRefreshedEvent{Context: ctx, ChangedKeys: changed}, true)
+		})
+	}
+
+	return callbacks, listErr, nil
+}
+
+/**
+Binds properties of the initialized refreshable bean, returns the bean to call OnRefresh
+*/
+func (t *context) refreshBean(b *bean) (RefreshableBean, bool, error) {
+
+	refreshable, ok := b.obj.(RefreshableBean)
+	if !ok || b.lifecycle != BeanInitialized {
+		return nil, false, nil
+	}
+
+	b.ctorMu.Lock()
+	defer b.ctorMu.Unlock()
+
+	if Verbose {
+		fmt.Printf("Refresh: bind bean '%s' with type '%v'\n", b.name, b.beanDef.classPtr)
+	}
+
+	if err := t.rebindProperties(b.valuePtr, b.beanDef); err != nil {
+		return nil, false, errors.Errorf("refresh bean '%s' failed, %v", b.name, err)
+	}
+	return refreshable, true, nil
+}
+
+/**
+Binds properties into the copy of the bean and assigns bound fields only if all of them are valid, so the bean is never half rebound
+*/
+func (t *context) rebindProperties(valuePtr reflect.Value, bd *beanDef) error {
+	if len(bd.properties) == 0 && bd.config == nil {
+		return nil
+	}
+	bound := reflect.New(bd.classPtr.Elem())
+	bound.Elem().Set(valuePtr.Elem())
+	if err := t.bindProperties(bound, bd); err != nil {
+		return err
+	}
+	value, boundValue := valuePtr.Elem(), bound.Elem()
+	for _, def := range bd.properties {
+		value.Field(def.fieldNum).Set(boundValue.Field(def.fieldNum))
+	}
+	if bd.config != nil {
+		assignConfig(value, boundValue)
+	}
+	return nil
+}
+
+func (t *context) OnPropertyChange(key string, listener PropertyListener) func() {
+	l := &propertyListener{fn: listener}
+
+	t.properties.mu.Lock()
+	t.properties.listeners[key] = append(t.properties.listeners[key], l)
+	t.properties.mu.Unlock()
+
+	return func() {
+		t.properties.mu.Lock()
+		defer t.properties.mu.Unlock()
+		var list []*propertyListener
+		for _, el := range t.properties.listeners[key] {
+			if el != l {
+				list = append(list, el)
+			}
+		}
+		if len(list) > 0 {
+			t.properties.listeners[key] = list
+		} else {
+			delete(t.properties.listeners, key)
+		}
+	}
+}
+
+func (t *context) notifyListeners(changed []string) {
+	for _, key := range changed {
+		t.properties.mu.RLock()
+		listeners := append([]*propertyListener(nil), t.properties.listeners[key]...)
+		t.properties.mu.RUnlock()
+
+		if len(listeners) == 0 {
+			continue
+		}
+		value, _ := t.Property(key)
+		for _, l := range listeners {
+			l.fn(key, value)
+		}
+	}
+}
+
+/**
+Refreshes the context periodically, useful together with PropertyFile source to poll the file.
+The onError callback is optional, returns function that stops refreshing.
+*/
+func RefreshEvery(ctx Context, interval time.Duration, onError func(error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				refresh(ctx, onError)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return stopOnce(done, ticker.Stop)
+}
+
+/**
+Refreshes the context on signal, SIGHUP by default.
+The onError callback is optional, returns function that stops refreshing.
+*/
+func RefreshOnSignal(ctx Context, onError func(error), sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				refresh(ctx, onError)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return stopOnce(done, func() {
+		signal.Stop(ch)
+	})
+}
+
+func refresh(ctx Context, onError func(error)) {
+	if err := ctx.Refresh(); err != nil {
+		if onError != nil {
+			onError(err)
+		} else if Verbose {
+			fmt.Printf("Refresh failed, %v\n", err)
+		}
+	}
+}
+
+func stopOnce(done chan struct{}, cb func()) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cb()
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type refreshableConfig struct {
+	Level   string `value:"log.level,default=info"`
+	Port    int    `value:"server.port"`
+	changed []string
+}
+
+func (t *refreshableConfig) OnRefresh(changedKeys []string) error {
+	t.changed = changedKeys
+	return nil
+}
+
+type staticConfig struct {
+	Level string `value:"log.level,default=info"`
+}
+
+func TestRefresh(t *testing.T) {
+
+	beans.Verbose = true
+
+	source := &lockedSource{values: map[string]string{"server.port": "8080"}}
+	refreshable := &refreshableConfig{}
+	static := &staticConfig{}
+	ctx, err := beans.Create(source, refreshable, static)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, "info", refreshable.Level)
+
+	var notified []string
+	cancel := ctx.OnPropertyChange("log.level", func(key, value string) {
+		notified = append(notified, value)
+	})
+
+	source.set("log.level", "debug")
+	source.set("server.port", "9090")
+	require.NoError(t, ctx.Refresh())
+
+	require.Equal(t, "debug", refreshable.Level)
+	require.Equal(t, 9090, refreshable.Port)
+	require.Equal(t, []string{"log.level", "server.port"}, refreshable.changed)
+	require.Equal(t, "info", static.Level)
+	require.Equal(t, []string{"debug"}, notified)
+
+	// nothing changed
+	refreshable.changed = nil
+	require.NoError(t, ctx.Refresh())
+	require.Nil(t, refreshable.changed)
+
+	cancel()
+	source.remove("log.level")
+	require.NoError(t, ctx.Refresh())
+	require.Equal(t, "info", refreshable.Level)
+	require.Equal(t, []string{"log.level"}, refreshable.changed)
+	require.Equal(t, []string{"debug"}, notified)
+
+	// required property removed
+	source.remove("server.port")
+	require.Error(t, ctx.Refresh())
+}
+
+func TestRefreshChildContext(t *testing.T) {
+
+	source := &lockedSource{values: map[string]string{"server.port": "8080"}}
+	ctx, err := beans.Create(source)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	refreshable := &refreshableConfig{}
+	child, err := ctx.Extend(refreshable)
+	require.NoError(t, err)
+	defer child.Close()
+
+	source.set("server.port", "9090")
+	require.NoError(t, ctx.Refresh())
+	require.Equal(t, 9090, refreshable.Port)
+}
+
+func TestRefreshMalformed(t *testing.T) {
+
+	source := &lockedSource{values: map[string]string{"server.port": "8080"}}
+	refreshable := &refreshableConfig{}
+	ctx, err := beans.Create(source, refreshable)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	// the level is valid, but the port is not, the bean keeps all previous values
+	source.set("log.level", "debug")
+	source.set("server.port", "http")
+	require.Error(t, ctx.Refresh())
+	require.Equal(t, "info", refreshable.Level)
+	require.Equal(t, 8080, refreshable.Port)
+	require.Nil(t, refreshable.changed)
+}
+
+func TestPropertyMapSnapshot(t *testing.T) {
+
+	values := map[string]string{"server.port": "8080"}
+	ctx, err := beans.Create(beans.PropertyMap(values))
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	values["server.port"] = "9090"
+	require.NoError(t, ctx.Refresh())
+	port, _ := ctx.Property("server.port")
+	require.Equal(t, "8080", port)
+}
+
+func TestRefreshFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "application.properties")
+	require.NoError(t, os.WriteFile(path, []byte("server.port=8080\n"), 0644))
+
+	refreshable := &refreshableConfig{}
+	ctx, err := beans.Create(beans.PropertyFile(path), refreshable)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	changed := make(chan string, 1)
+	ctx.OnPropertyChange("server.port", func(key, value string) {
+		changed <- value
+	})
+
+	stop := beans.RefreshEvery(ctx, 10*time.Millisecond, nil)
+	defer stop()
+
+	require.NoError(t, os.WriteFile(path, []byte("server.port=9090\nlog.level=debug\n"), 0644))
+	select {
+	case value := <-changed:
+		require.Equal(t, "9090", value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("file change is not detected")
+	}
+	stop()
+	stop()
+}
+
+type lockedSource struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (t *lockedSource) set(key, value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.values[key] = value
+}
+
+func (t *lockedSource) remove(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.values, key)
+}
+
+func (t *lockedSource) Properties() (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	values := make(map[string]string, len(t.values))
+	for key, value := range t.values {
+		values[key] = value
+	}
+	return values, nil
+}
+
+func TestRefreshOnSignal(t *testing.T) {
+
+	// signal delivery does not synchronize memory, so the source is guarded
+	source := &lockedSource{values: map[string]string{"server.port": "8080"}}
+	ctx, err := beans.Create(source)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	changed := make(chan string, 1)
+	ctx.OnPropertyChange("server.port", func(key, value string) {
+		changed <- value
+	})
+
+	stop := beans.RefreshOnSignal(ctx, nil)
+	defer stop()
+
+	source.set("server.port", "9090")
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGHUP))
+
+	select {
+	case value := <-changed:
+		require.Equal(t, "9090", value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal is not handled")
+	}
+}
+
+type Refresher interface {
+	Refresh() error
+}
+
+type cacheRefresher struct {
+}
+
+func (t *cacheRefresher) Refresh() error {
+	return nil
+}
+
+type refresherHolder struct {
+	Refresher Refresher `inject`
+}
+
+func TestContextNotCandidateOfRefresher(t *testing.T) {
+
+	// the context has method Refresh too, but it is not the candidate of user interfaces
+	refresher := &cacheRefresher{}
+	holder := &refresherHolder{}
+	ctx, err := beans.Create(refresher, holder)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.True(t, holder.Refresher == refresher)
+}
+
+type reloadedPool struct {
+	constructed int
+}
+
+func (t *reloadedPool) PostConstruct() error {
+	t.constructed++
+	return nil
+}
+
+type reloadingPool struct {
+	Ctx  beans.Context `inject`
+	Size int           `value:"pool.size"`
+}
+
+func (t *reloadingPool) OnRefresh(changedKeys []string) error {
+	for _, b := range t.Ctx.Bean(reflect.TypeOf((*reloadedPool)(nil)), beans.DefaultLevel) {
+		if err := b.Reload(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRefreshReloadFromListener(t *testing.T) {
+
+	source := &lockedSource{values: map[string]string{"pool.size": "1"}}
+	pool := &reloadedPool{}
+	ctx, err := beans.Create(source, pool, &reloadingPool{})
+	require.NoError(t, err)
+
+	var reloadErr error
+	ctx.OnPropertyChange("pool.size", func(key, value string) {
+		for _, b := range ctx.Bean(reflect.TypeOf(pool), beans.DefaultLevel) {
+			reloadErr = b.Reload()
+		}
+	})
+
+	source.set("pool.size", "2")
+	done := make(chan error, 1)
+	go func() {
+		done <- ctx.Refresh()
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		// the context is not closed, Close would be blocked too
+		t.Fatal("reload from the refresh listener is blocked")
+	}
+	require.NoError(t, reloadErr)
+
+	// reloaded by OnRefresh and by the property listener
+	require.Equal(t, 3, pool.constructed)
+	require.Equal(t, uint64(2), ctx.Metrics().Reloads)
+	require.NoError(t, ctx.Close())
+}
@@ -49,3 +49,65 @@ func (t *registry) addBean(ifaceType reflect.Type, b *bean) {
 	t.beansByName[b.name] = append(t.beansByName[b.name], b)
 }
 
+
+func (t *registry) types() []reflect.Type {
+	t.RLock()
+	defer t.RUnlock()
+	var list []reflect.Type
+	for ifaceType := range t.beansByType {
+		list = append(list, ifaceType)
+	}
+	return list
+}
+
+/**
+Adds bean registered on runtime to the type if it is not there yet
+*/
+func (t *registry) addNewBean(ifaceType reflect.Type, b *bean) {
+	t.Lock()
+	defer t.Unlock()
+	for _, el := range t.beansByType[ifaceType] {
+		if el == b {
+			return
+		}
+	}
+	t.beansByType[ifaceType] = append(t.beansByType[ifaceType], b)
+	for _, el := range t.beansByName[b.name] {
+		if el == b {
+			return
+		}
+	}
+	t.beansByName[b.name] = append(t.beansByName[b.name], b)
+}
+
+/**
+Removes bean from all types and names, next lookup of empty type would search it in context
+*/
+func (t *registry) removeBean(b *bean) {
+	t.Lock()
+	defer t.Unlock()
+	for ifaceType, list := range t.beansByType {
+		if list = removeFromList(list, b); len(list) > 0 {
+			t.beansByType[ifaceType] = list
+		} else {
+			delete(t.beansByType, ifaceType)
+		}
+	}
+	for name, list := range t.beansByName {
+		if list = removeFromList(list, b); len(list) > 0 {
+			t.beansByName[name] = list
+		} else {
+			delete(t.beansByName, name)
+		}
+	}
+}
+
+func removeFromList(list []*bean, b *bean) []*bean {
+	var out []*bean
+	for _, el := range list {
+		if el != b {
+			out = append(out, el)
+		}
+	}
+	return out
+}
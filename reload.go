@@ -0,0 +1,363 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"time"
+)
+
+func (t *context) Reload(instance Bean) (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer t.countReload(&err)
+
+	endTrace := t.traceReload(instance)
+	defer func() {
+		endTrace(err)
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("reload bean recovered with error %v", r)
+		}
+	}()
+
+	b, ok := instance.(*bean)
+	if !ok || !t.hasCoreBean(b) {
+		return errors.Errorf("bean '%v' is not registered in context", instance)
+	}
+
+	if b.beenFactory != nil {
+		return errors.Errorf("bean '%s' was created by factory bean '%v', use ReloadFactory instead", b.name, b.beenFactory.factoryClassPtr)
+	}
+
+	// runtime injectors must not hold beans under reload
+	t.changed()
+	defer t.changed()
+
+	if err := t.reloadObject(b); err != nil {
+		return err
+	}
+	return t.Publish(BeanReloadedEvent{Bean: b})
+}
+
+func (t *context) ReloadCascade(instance Bean) (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer t.countReload(&err)
+
+	endTrace := t.traceReload(instance)
+	defer func() {
+		endTrace(err)
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("reload bean recovered with error %v", r)
+		}
+	}()
+
+	b, ok := instance.(*bean)
+	if !ok || !t.hasCoreBean(b) {
+		return errors.Errorf("bean '%v' is not registered in context", instance)
+	}
+
+	if b.beenFactory != nil {
+		return errors.Errorf("bean '%s' was created by factory bean '%v', use ReloadFactory instead", b.name, b.beenFactory.factoryClassPtr)
+	}
+
+	err = t.reloadCascade(b, func() error {
+		return t.reloadObject(b)
+	})
+	if err != nil {
+		return err
+	}
+	return t.Publish(BeanReloadedEvent{Bean: b})
+}
+
+func (t *context) ReloadFactory(instance Bean) (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer t.countReload(&err)
+
+	endTrace := t.traceReload(instance)
+	defer func() {
+		endTrace(err)
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("reload factory bean recovered with error %v", r)
+		}
+	}()
+
+	b, ok := instance.(*bean)
+	if !ok {
+		return errors.Errorf("bean '%v' is not registered in context", instance)
+	}
+
+	f := b.beenFactory
+	if f == nil {
+		for _, el := range t.coreBeans() {
+			if el.beenFactory != nil && el.beenFactory.bean == b {
+				f = el.beenFactory
+				break
+			}
+		}
+	}
+
+	if f == nil || !t.hasCoreBean(f.bean) {
+		return errors.Errorf("bean '%v' is not a factory bean or produced by factory bean in context", instance)
+	}
+
+	if !f.factoryBean.Singleton() {
+		return errors.Errorf("factory bean '%v' produces non-singleton objects that can not be re-injected", f.factoryClassPtr)
+	}
+
+	product := f.instances[0]
+
+	err = t.reloadCascade(product, func() error {
+		if err := t.reloadObject(f.bean); err != nil {
+			return err
+		}
+		return t.reproduce(f, product)
+	})
+	if err != nil {
+		return err
+	}
+	return t.Publish(BeanReloadedEvent{Bean: product})
+}
+
+/**
+Counts the reload or the reload failure by the result of the call
+*/
+func (t *context) countReload(err *error) {
+	if *err != nil {
+		t.metrics.fail(PhaseReload)
+	} else {
+		t.metrics.reloads.Add(1)
+	}
+}
+
+/**
+Destroys dependents of the target in reverse initialization order, reloads target and initializes dependents in initialization order
+*/
+func (t *context) reloadCascade(target *bean, reload func() error) error {
+
+	// runtime injectors must not hold beans under reload
+	t.changed()
+	defer t.changed()
+
+	dependents := t.dependentBeans(target)
+
+	n := len(dependents)
+	for j := n - 1; j >= 0; j-- {
+		if err := t.destroyObject(dependents[j]); err != nil {
+			return err
+		}
+	}
+
+	if err := reload(); err != nil {
+		return err
+	}
+
+	for _, b := range dependents {
+		if err := t.initObject(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+Calls Destroy and PostConstruct on the bean
+*/
+func (t *context) reloadObject(b *bean) error {
+	if err := t.destroyObject(b); err != nil {
+		return err
+	}
+	return t.initObject(b)
+}
+
+func (t *context) destroyObject(b *bean) error {
+	b.ctorMu.Lock()
+	defer b.ctorMu.Unlock()
+
+	if Verbose {
+		fmt.Printf("Reload: destroy bean '%s' with type '%v'\n", b.name, b.beanDef.classPtr)
+	}
+
+	b.lifecycle = BeanDestroying
+	if dis, ok := b.obj.(DisposableBean); ok {
+		endCall := t.traceCall(SpanDestroy, b, nil)
+		start := time.Now()
+		err := dis.Destroy()
+		endCall(err)
+		if err != nil {
+			return errors.Errorf("destroy bean '%s' failed on reload, %v", b.name, err)
+		}
+		t.metrics.destroy.observe(time.Since(start))
+	}
+	b.lifecycle = BeanDestroyed
+	return nil
+}
+
+func (t *context) initObject(b *bean) error {
+	b.ctorMu.Lock()
+	defer b.ctorMu.Unlock()
+
+	if Verbose {
+		fmt.Printf("Reload: initialize bean '%s' with type '%v'\n", b.name, b.beanDef.classPtr)
+	}
+
+	b.lifecycle = BeanConstructing
+	if init, ok := b.obj.(InitializingBean); ok {
+		endCall := t.traceCall(SpanPostConstruct, b, nil)
+		start := time.Now()
+		err := init.PostConstruct()
+		endCall(err)
+		if err != nil {
+			return errors.Errorf("post construct bean '%s' failed on reload, %v", b.name, err)
+		}
+		t.observeConstruct(b, start)
+	}
+	b.lifecycle = BeanInitialized
+	return nil
+}
+
+/**
+Produces the new singleton object by factory and injects it in to all fields holding the old one
+*/
+func (t *context) reproduce(f *factory, product *bean) error {
+
+	endCall := t.traceCall(SpanObject, f.bean, nil)
+	obj, err := f.produce(nil)
+	endCall(err)
+	if err != nil {
+		return err
+	}
+
+	var refs []reference
+	for _, ctx := range t.descendants() {
+		for _, consumer := range ctx.coreBeans() {
+			refs = append(refs, consumer.references(product)...)
+		}
+	}
+
+	prev := product.valuePtr
+	next := reflect.ValueOf(obj)
+
+	product.ctorMu.Lock()
+	product.obj = obj
+	product.valuePtr = next
+	product.lifecycle = BeanInitialized
+	product.ctorMu.Unlock()
+
+	for _, ref := range refs {
+		if Verbose {
+			fmt.Printf("Reload: inject new object of factory '%v' in to %v\n", f.factoryClassPtr, ref)
+		}
+		ref.replace(prev, next)
+	}
+
+	return nil
+}
+
+/**
+Returns all beans in current and child contexts that depend on target directly or transitively, in initialization order
+*/
+func (t *context) dependentBeans(target *bean) []*bean {
+
+	var all []*bean
+	for _, ctx := range t.descendants() {
+		all = append(all, ctx.coreBeans()...)
+	}
+
+	found := map[*bean]bool{target: true}
+	queue := []*bean{target}
+	var dependents []*bean
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, b := range all {
+			if !found[b] && b.dependsOn(current) {
+				found[b] = true
+				queue = append(queue, b)
+				dependents = append(dependents, b)
+			}
+		}
+	}
+
+	return initOrder(dependents)
+}
+
+/**
+Orders beans, so dependencies go first
+*/
+func initOrder(list []*bean) []*bean {
+	ordered := make([]*bean, 0, len(list))
+	inList := make(map[*bean]bool, len(list))
+	for _, b := range list {
+		inList[b] = true
+	}
+	visited := make(map[*bean]bool, len(list))
+	var visit func(b *bean)
+	visit = func(b *bean) {
+		if visited[b] {
+			return
+		}
+		visited[b] = true
+		b.forEachDependency(func(dep *bean) {
+			if dep != b && inList[dep] {
+				visit(dep)
+			}
+		})
+		ordered = append(ordered, b)
+	}
+	for _, b := range list {
+		visit(b)
+	}
+	return ordered
+}
+
+/**
+Calls the function for every bean that the bean depends on, see dependsOn
+*/
+func (t *bean) forEachDependency(fn func(dep *bean)) {
+	for _, dep := range t.dependencies {
+		fn(dep)
+	}
+	for _, factoryDep := range t.factoryDependencies {
+		fn(factoryDep.factory.bean)
+		for _, product := range factoryDep.factory.instances {
+			fn(product)
+		}
+	}
+}
+
+/**
+Checks if the bean was initialized after target, because it uses target or the object produced by target
+*/
+func (t *bean) dependsOn(target *bean) bool {
+	if containsBean(t.dependencies, target) {
+		return true
+	}
+	for _, factoryDep := range t.factoryDependencies {
+		if factoryDep.factory.bean == target || factoryDep.factory == target.beenFactory {
+			return true
+		}
+	}
+	return false
+}
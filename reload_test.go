@@ -67,3 +67,160 @@ func TestBeanReload(t *testing.T) {
 	require.True(t, tBean.ReloadableBean == reBean)
 
 }
+
+type reloadJournal struct {
+	events []string
+}
+
+func (t *reloadJournal) add(event string) {
+	t.events = append(t.events, event)
+}
+
+type reloadConfig struct {
+	Journal *reloadJournal `inject`
+	version int
+}
+
+func (t *reloadConfig) PostConstruct() error {
+	t.version++
+	t.Journal.add("init config")
+	return nil
+}
+
+func (t *reloadConfig) Destroy() error {
+	t.Journal.add("destroy config")
+	return nil
+}
+
+type reloadService struct {
+	Journal *reloadJournal `inject`
+	Config  *reloadConfig  `inject`
+	version int
+}
+
+func (t *reloadService) PostConstruct() error {
+	t.version = t.Config.version
+	t.Journal.add("init service")
+	return nil
+}
+
+func (t *reloadService) Destroy() error {
+	t.Journal.add("destroy service")
+	return nil
+}
+
+type reloadHandler struct {
+	Journal *reloadJournal `inject`
+	Service *reloadService `inject`
+	version int
+}
+
+func (t *reloadHandler) PostConstruct() error {
+	t.version = t.Service.version
+	t.Journal.add("init handler")
+	return nil
+}
+
+func (t *reloadHandler) Destroy() error {
+	t.Journal.add("destroy handler")
+	return nil
+}
+
+func TestBeanReloadCascade(t *testing.T) {
+
+	beans.Verbose = true
+
+	journal := &reloadJournal{}
+	config := &reloadConfig{}
+	handler := &reloadHandler{}
+
+	ctx, err := beans.Create(
+		journal,
+		handler,
+		&reloadService{},
+		config,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, []string{"init config", "init service", "init handler"}, journal.events)
+	require.Equal(t, 1, handler.version)
+
+	journal.events = nil
+	list := ctx.Bean(reflect.TypeOf(config), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	err = ctx.ReloadCascade(list[0])
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"destroy handler", "destroy service", "destroy config", "init config", "init service", "init handler"}, journal.events)
+	require.Equal(t, 2, handler.version)
+	require.Equal(t, beans.BeanInitialized, list[0].Lifecycle())
+}
+
+type reloadProduct struct {
+	generation int
+}
+
+type reloadProductFactory struct {
+	generation int
+}
+
+func (t *reloadProductFactory) PostConstruct() error {
+	t.generation++
+	return nil
+}
+
+func (t *reloadProductFactory) Object() (*reloadProduct, error) {
+	return &reloadProduct{generation: t.generation}, nil
+}
+
+func (t *reloadProductFactory) ObjectName() string {
+	return ""
+}
+
+func (t *reloadProductFactory) Singleton() bool {
+	return true
+}
+
+type reloadProductConsumer struct {
+	Product    *reloadProduct   `inject`
+	Products   []*reloadProduct `inject`
+	generation int
+}
+
+func (t *reloadProductConsumer) PostConstruct() error {
+	t.generation = t.Product.generation
+	return nil
+}
+
+func TestFactoryReload(t *testing.T) {
+
+	beans.Verbose = true
+
+	consumer := &reloadProductConsumer{}
+	ctx, err := beans.Create(
+		&reloadProductFactory{},
+		consumer,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, 1, consumer.generation)
+	prev := consumer.Product
+
+	list := ctx.Bean(reflect.TypeOf(prev), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	err = ctx.ReloadCascade(list[0])
+	require.Error(t, err)
+
+	err = ctx.ReloadFactory(list[0])
+	require.NoError(t, err)
+
+	require.NotEqual(t, prev, consumer.Product)
+	require.Equal(t, 2, consumer.Product.generation)
+	require.Equal(t, consumer.Product, consumer.Products[0])
+	require.Equal(t, 2, consumer.generation)
+	require.Equal(t, consumer.Product, list[0].Object())
+}
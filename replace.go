@@ -0,0 +1,224 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	gocontext "context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"time"
+)
+
+func (t *context) Replace(instance Bean, obj interface{}) (err error) {
+
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	defer t.countReload(&err)
+
+	endTrace := t.traceReload(instance)
+	defer func() {
+		endTrace(err)
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("replace bean recovered with error %v", r)
+		}
+	}()
+
+	b, ok := instance.(*bean)
+	if !ok || !t.hasCoreBean(b) {
+		return errors.Errorf("bean '%v' is not registered in context", instance)
+	}
+
+	if b.obj == t {
+		return errors.New("context bean can not be replaced")
+	}
+
+	if b.beenFactory != nil {
+		return errors.Errorf("bean '%s' was created by factory bean '%v', use ReloadFactory instead", b.name, b.beenFactory.factoryClassPtr)
+	}
+
+	if b.beanDef.factory {
+		return errors.Errorf("factory bean '%s' can not be replaced, use ReloadFactory instead", b.name)
+	}
+
+	if _, isMultiFactoryBean := b.obj.(MultiFactoryBean); isMultiFactoryBean {
+		return errors.Errorf("multi factory bean '%s' can not be replaced", b.name)
+	}
+
+	classPtr := b.beanDef.classPtr
+	if classPtr.Kind() != reflect.Ptr {
+		return errors.Errorf("bean '%s' with type '%v' is not a pointer and can not be replaced", b.name, classPtr)
+	}
+
+	if obj == nil || reflect.TypeOf(obj) != classPtr {
+		return errors.Errorf("object of type '%v' can not replace bean '%s' with type '%v'", reflect.TypeOf(obj), b.name, classPtr)
+	}
+
+	if obj == b.obj {
+		return errors.Errorf("bean '%s' already holds the object", b.name)
+	}
+
+	next, err := investigate(obj, classPtr)
+	if err != nil {
+		return err
+	}
+
+	if _, isNamed := obj.(NamedBean); isNamed && next.name != b.name {
+		return errors.Errorf("object with name '%s' can not replace bean '%s'", next.name, b.name)
+	}
+	next.name = b.name
+	next.qualifier = b.qualifier
+
+	collections, err := t.injectReplacement(b, next)
+	if err != nil {
+		return err
+	}
+
+	if err := t.bindProperties(next.valuePtr, next.beanDef); err != nil {
+		return err
+	}
+
+	if err := t.constructBean(next, nil); err != nil {
+		return err
+	}
+	// the bean itself goes to disposables, not the temporary one
+	t.disposables = removeFromList(t.disposables, next)
+
+	if _, ok := obj.(Lifecycle); ok && t.running.Load() {
+		if err := startBean(gocontext.Background(), next); err != nil {
+			// the bean keeps the previous object, the new one is not referenced by the context
+			t.metrics.fail(PhaseLifecycle)
+			if e := t.destroyBean(next); e != nil {
+				return errors.Errorf("%v, destroy failed, %v", err, e)
+			}
+			return err
+		}
+	}
+
+	var refs []reference
+	for _, ctx := range t.descendants() {
+		for _, consumer := range ctx.coreBeans() {
+			if consumer != b {
+				refs = append(refs, consumer.references(b)...)
+			}
+		}
+	}
+
+	prev := b.valuePtr
+	prevObj := b.obj
+
+	b.ctorMu.Lock()
+	b.obj = obj
+	b.valuePtr = next.valuePtr
+	b.beanDef = next.beanDef
+	b.ordered = next.ordered
+	b.order = next.order
+	b.dependencies = next.dependencies
+	b.factoryDependencies = next.factoryDependencies
+	b.lifecycle = BeanInitialized
+	b.ctorMu.Unlock()
+	t.changed()
+
+	_, wasDisposable := prevObj.(DisposableBean)
+	_, isDisposable := obj.(DisposableBean)
+	switch {
+	case wasDisposable && !isDisposable:
+		t.disposables = removeFromList(t.disposables, b)
+	case !wasDisposable && isDisposable:
+		t.disposables = append(t.disposables, b)
+	}
+
+	var list []*injection
+	for _, inject := range t.collections {
+		if inject.bean != b {
+			list = append(list, inject)
+		}
+	}
+	t.collections = append(list, collections...)
+
+	for _, ref := range refs {
+		if Verbose {
+			fmt.Printf("Replace: inject new object of bean '%s' in to %v\n", b.name, ref)
+		}
+		ref.replace(prev, next.valuePtr)
+	}
+
+	if _, ok := prevObj.(Service); ok {
+		t.cancelService(b)
+	}
+	if _, ok := obj.(Service); ok && t.created.Load() {
+		t.launchService(b)
+	}
+
+	// the new object is already in use, so the previous one is stopped and destroyed and the event is published even on errors
+	var listErr []error
+	if _, ok := prevObj.(Lifecycle); ok {
+		prevBean := &bean{name: b.name, obj: prevObj, beanDef: &beanDef{classPtr: classPtr}}
+		if err := stopBean(gocontext.Background(), prevBean); err != nil {
+			listErr = append(listErr, err)
+		}
+	}
+
+	if wasDisposable {
+		if Verbose {
+			fmt.Printf("Replace: destroy previous object of bean '%s' with type '%v'\n", b.name, classPtr)
+		}
+		endCall := t.traceCall(SpanDestroy, b, nil)
+		start := time.Now()
+		err := prevObj.(DisposableBean).Destroy()
+		t.metrics.destroy.observe(time.Since(start))
+		endCall(err)
+		if err != nil {
+			t.metrics.fail(PhaseDestroy)
+			listErr = append(listErr, errors.Errorf("destroy previous object of bean '%s' failed on replace, %v", b.name, err))
+		}
+	}
+
+	if err := t.Publish(BeanReloadedEvent{Bean: b}); err != nil {
+		listErr = append(listErr, err)
+	}
+
+	return multipleErr(listErr)
+}
+
+/**
+Injects fields of the new object, returns collections with 'dynamic' option tracked for the replaced bean
+*/
+func (t *context) injectReplacement(b *bean, next *bean) ([]*injection, error) {
+
+	var collections []*injection
+	value := next.valuePtr.Elem()
+
+	for _, def := range next.beanDef.fields {
+
+		var deep []beanlist
+		if def.fieldType.Kind() == reflect.Interface {
+			deep = t.searchCandidatesRecursive(def.fieldType)
+		} else {
+			deep = t.findDirectRecursive(def.fieldType)
+		}
+
+		if len(deep) == 0 {
+			if !def.optional {
+				return nil, errors.Errorf("can not find candidates to inject the required field '%s' in class '%v'", def.fieldName, def.class)
+			}
+		} else {
+			inject := &injection{bean: next, value: value, injectionDef: def}
+			if err := inject.inject(deep); err != nil {
+				return nil, err
+			}
+		}
+
+		if def.dynamic {
+			collections = append(collections, &injection{bean: b, value: value, injectionDef: def})
+		}
+	}
+
+	return collections, nil
+}
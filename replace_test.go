@@ -0,0 +1,238 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var GreeterClass = reflect.TypeOf((*Greeter)(nil)).Elem()
+
+type Greeter interface {
+	Greet() string
+}
+
+type greeterService struct {
+	Runtime     *pluginRuntime `inject`
+	greeting    string
+	constructed int
+	destroyed   int
+}
+
+func (t *greeterService) Greet() string {
+	return t.greeting
+}
+
+func (t *greeterService) PostConstruct() error {
+	t.constructed++
+	return nil
+}
+
+func (t *greeterService) Destroy() error {
+	t.destroyed++
+	return nil
+}
+
+type greeterConsumer struct {
+	Service  *greeterService            `inject`
+	Greeter  Greeter                    `inject`
+	Greeters []*greeterService          `inject`
+	Table    map[string]*greeterService `inject`
+}
+
+type greeterChildConsumer struct {
+	Service *greeterService `inject`
+}
+
+func TestReplace(t *testing.T) {
+
+	beans.Verbose = true
+
+	first := &greeterService{greeting: "hello"}
+	consumer := &greeterConsumer{}
+	ctx, err := beans.Create(
+		&pluginRuntime{},
+		first,
+		consumer,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	childConsumer := &greeterChildConsumer{}
+	child, err := ctx.Extend(childConsumer)
+	require.NoError(t, err)
+	defer child.Close()
+
+	list := ctx.Bean(GreeterClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	second := &greeterService{greeting: "hi"}
+
+	// callers already working with the previous object and collections finish with them
+	inFlight := childConsumer.Service
+	greeters := consumer.Greeters
+	table := consumer.Table
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			require.Equal(t, "hello", inFlight.Greet())
+			for _, g := range table {
+				require.Equal(t, "hello", g.Greet())
+			}
+		}
+	}()
+
+	err = ctx.Replace(list[0], second)
+	require.NoError(t, err)
+	wg.Wait()
+
+	require.Equal(t, first, greeters[0])
+	require.Equal(t, first, table["*beans_test.greeterService"])
+	require.Equal(t, second, consumer.Table["*beans_test.greeterService"])
+
+	require.NotNil(t, second.Runtime)
+	require.Equal(t, 1, second.constructed)
+	require.Equal(t, 0, second.destroyed)
+	require.Equal(t, 1, first.destroyed)
+
+	require.Equal(t, second, consumer.Service)
+	require.Equal(t, "hi", consumer.Greeter.Greet())
+	require.Equal(t, second, consumer.Greeters[0])
+	require.Equal(t, second, childConsumer.Service)
+
+	require.Equal(t, second, list[0].Object())
+	require.Equal(t, beans.BeanInitialized, list[0].Lifecycle())
+
+	// new object is destroyed by context close
+	require.NoError(t, child.Close())
+	require.NoError(t, ctx.Close())
+	require.Equal(t, 1, second.destroyed)
+	require.Equal(t, 1, first.destroyed)
+}
+
+func TestReplaceStartFailure(t *testing.T) {
+
+	journal := &lifecycleJournal{}
+	server := &phasedComponent{name: "server"}
+	ctx, err := beans.Create(journal, server)
+	require.NoError(t, err)
+
+	list := ctx.Bean(reflect.TypeOf(server), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	journal.records = nil
+	err = ctx.Replace(list[0], &phasedComponent{name: "server", fail: true})
+	require.Error(t, err)
+	require.Equal(t, []string{"destroy:server"}, journal.records)
+
+	// the bean keeps running previous object
+	require.True(t, list[0].Object() == server)
+	require.True(t, server.running)
+
+	journal.records = nil
+	require.NoError(t, ctx.Close())
+	require.Equal(t, []string{"stop:server", "destroy:server"}, journal.records)
+}
+
+func TestReplaceErrors(t *testing.T) {
+
+	ctx, err := beans.Create(
+		&pluginRuntime{},
+		&greeterService{greeting: "hello"},
+		&reloadProductFactory{},
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(GreeterClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	err = ctx.Replace(list[0], &pluginRuntime{})
+	require.Error(t, err)
+
+	err = ctx.Replace(list[0], list[0].Object())
+	require.Error(t, err)
+
+	product := ctx.Bean(reflect.TypeOf((*reloadProduct)(nil)), beans.DefaultLevel)
+	require.Equal(t, 1, len(product))
+	err = ctx.Replace(product[0], &reloadProduct{})
+	require.Error(t, err)
+
+	require.Equal(t, "hello", list[0].Object().(Greeter).Greet())
+}
+
+type stoppingGreeter struct {
+	greeting  string
+	running   bool
+	failStop  bool
+	destroyed int
+}
+
+func (t *stoppingGreeter) Greet() string {
+	return t.greeting
+}
+
+func (t *stoppingGreeter) Start(ctx context.Context) error {
+	t.running = true
+	return nil
+}
+
+func (t *stoppingGreeter) Stop(ctx context.Context) error {
+	if t.failStop {
+		return errors.New("stop failed")
+	}
+	t.running = false
+	return nil
+}
+
+func (t *stoppingGreeter) IsRunning() bool {
+	return t.running
+}
+
+func (t *stoppingGreeter) Destroy() error {
+	t.destroyed++
+	return nil
+}
+
+type replaceAudit struct {
+	reloaded []string
+}
+
+func (t *replaceAudit) OnEvent(event beans.BeanReloadedEvent) error {
+	t.reloaded = append(t.reloaded, event.Bean.Name())
+	return nil
+}
+
+func TestReplaceStopFailure(t *testing.T) {
+
+	first := &stoppingGreeter{greeting: "hello", failStop: true}
+	audit := &replaceAudit{}
+	ctx, err := beans.Create(first, audit)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(GreeterClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	second := &stoppingGreeter{greeting: "hi"}
+	err = ctx.Replace(list[0], second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "stop failed")
+
+	// the previous object is destroyed and the event is published anyway
+	require.Equal(t, second, list[0].Object())
+	require.Equal(t, 1, first.destroyed)
+	require.Equal(t, []string{"*beans_test.stoppingGreeter"}, audit.reloaded)
+	require.Equal(t, uint64(1), ctx.Metrics().Destroy.Count)
+}
@@ -0,0 +1,113 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+)
+
+var DefaultShutdownTimeout = 30 * time.Second
+
+/**
+Options of the application started by Run
+*/
+type RunOptions struct {
+
+	/**
+	Channel of signals that stop the application, by default SIGINT and SIGTERM are handled
+	*/
+	Signals <-chan os.Signal
+
+	/**
+	Maximum duration of Close, DefaultShutdownTimeout if zero
+	*/
+	ShutdownTimeout time.Duration
+
+	/**
+	Writer of errors, os.Stderr if nil
+	*/
+	Errors io.Writer
+}
+
+/**
+Runs the application: creates the context that starts Lifecycle beans and services, blocks until signal or escalated failure of the service,
+then closes the context in the bounded time.
+Returns ExitOK if application stopped by signal and closed without errors, otherwise ExitFailure.
+
+Example:
+	func main() {
+		os.Exit(beans.Run(beans.RunOptions{}, scan...))
+	}
+*/
+func Run(opts RunOptions, scan ...interface{}) int {
+
+	out := opts.Errors
+	if out == nil {
+		out = os.Stderr
+	}
+
+	timeout := opts.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	signals := opts.Signals
+	if signals == nil {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(ch)
+		signals = ch
+	}
+
+	ctx, err := Create(scan...)
+	if err != nil {
+		fmt.Fprintf(out, "create context failed, %v\n", err)
+		return ExitFailure
+	}
+
+	code := ExitOK
+	select {
+	case sig := <-signals:
+		if Verbose {
+			fmt.Printf("Run: received signal %v\n", sig)
+		}
+	case <-ctx.Done():
+		if err := ctx.Err(); err != nil {
+			fmt.Fprintf(out, "service failed, %v\n", err)
+			code = ExitFailure
+		}
+	}
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- ctx.Close()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			fmt.Fprintf(out, "close context failed, %v\n", err)
+			code = ExitFailure
+		}
+	case <-timer.C:
+		fmt.Fprintf(out, "close context timed out after %v\n", timeout)
+		code = ExitFailure
+	}
+
+	return code
+}
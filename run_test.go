@@ -0,0 +1,78 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type stuckService struct {
+	started chan struct{}
+}
+
+func (t *stuckService) Run(ctx context.Context) error {
+	close(t.started)
+	<-ctx.Done()
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestRunSignal(t *testing.T) {
+
+	signals := make(chan os.Signal, 1)
+	service := &tickerService{started: make(chan struct{})}
+
+	go func() {
+		<-service.started
+		signals <- syscall.SIGTERM
+	}()
+
+	code := beans.Run(beans.RunOptions{Signals: signals}, service)
+	require.Equal(t, beans.ExitOK, code)
+	require.True(t, service.cancelled.Load())
+	require.True(t, service.destroyed.Load())
+}
+
+func TestRunServiceFailure(t *testing.T) {
+
+	var out bytes.Buffer
+	service := &flakyService{policy: beans.ServicePolicy{Escalate: true}}
+
+	code := beans.Run(beans.RunOptions{Signals: make(chan os.Signal), Errors: &out}, service)
+	require.Equal(t, beans.ExitFailure, code)
+	require.True(t, strings.Contains(out.String(), "run 1 failed"), out.String())
+}
+
+func TestRunCreateFailure(t *testing.T) {
+
+	var out bytes.Buffer
+	code := beans.Run(beans.RunOptions{Signals: make(chan os.Signal), Errors: &out}, &serverConfig{})
+	require.Equal(t, beans.ExitFailure, code)
+	require.True(t, strings.Contains(out.String(), "create context failed"))
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+
+	var out bytes.Buffer
+	signals := make(chan os.Signal, 1)
+	service := &stuckService{started: make(chan struct{})}
+
+	go func() {
+		<-service.started
+		signals <- syscall.SIGINT
+	}()
+
+	code := beans.Run(beans.RunOptions{Signals: signals, ShutdownTimeout: 10 * time.Millisecond, Errors: &out}, service)
+	require.Equal(t, beans.ExitFailure, code)
+	require.True(t, strings.Contains(out.String(), "timed out"))
+}
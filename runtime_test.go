@@ -0,0 +1,260 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type runtimeElement struct {
+	name string
+}
+
+func (t *runtimeElement) BeanName() string {
+	return t.name
+}
+
+type runtimeMissing struct {
+}
+
+type runtimeHolder struct {
+	Elements []*runtimeElement          `inject`
+	Table    map[string]*runtimeElement `inject`
+	Missing  *runtimeMissing            `inject:"optional"`
+	Loggers  []*scopedLogger            `inject`
+}
+
+func TestRuntimeInjectCollections(t *testing.T) {
+
+	beans.Verbose = true
+
+	ctx, err := beans.Create(
+		&runtimeElement{name: "first"},
+		&runtimeElement{name: "second"},
+		&scopedLoggerFactory{},
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	holder := &runtimeHolder{}
+	err = ctx.Inject(holder)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(holder.Elements))
+	require.Equal(t, 2, len(holder.Table))
+	require.Equal(t, "first", holder.Table["first"].name)
+	require.Equal(t, "second", holder.Table["second"].name)
+	require.Nil(t, holder.Missing)
+	require.Equal(t, 1, len(holder.Loggers))
+	require.Equal(t, "*beans_test.runtimeHolder.Loggers", holder.Loggers[0].scope)
+
+	// second injection replaces collections
+	err = ctx.Inject(holder)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(holder.Elements))
+}
+
+func TestRuntimeInjectMissing(t *testing.T) {
+
+	ctx, err := beans.Create()
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	err = ctx.Inject(&struct {
+		Missing *runtimeMissing `inject`
+	}{})
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "Missing"))
+}
+
+type lazyRuntimeTarget struct {
+	Starter *lazyRuntimeStarter `inject`
+}
+
+type lazyRuntimeStarter struct {
+	Context beans.Context `inject`
+	testing *testing.T
+}
+
+func (t *lazyRuntimeStarter) PostConstruct() error {
+	// target is not initialized yet, since it depends on the starter
+	err := t.Context.Inject(&struct {
+		Target *lazyRuntimeTarget `inject`
+	}{})
+	require.Error(t.testing, err)
+
+	lazy := &struct {
+		Target *lazyRuntimeTarget `inject:"lazy"`
+	}{}
+	err = t.Context.Inject(lazy)
+	require.NoError(t.testing, err)
+	require.NotNil(t.testing, lazy.Target)
+	return nil
+}
+
+func TestRuntimeInjectLazy(t *testing.T) {
+
+	beans.Verbose = true
+
+	ctx, err := beans.Create(
+		&lazyRuntimeTarget{},
+		&lazyRuntimeStarter{testing: t},
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+}
+
+type buildProcessor struct {
+	Element     *runtimeElement `inject:"bean=first"`
+	constructed int
+	destroyed   int
+}
+
+func (t *buildProcessor) PostConstruct() error {
+	t.constructed++
+	return nil
+}
+
+func (t *buildProcessor) Destroy() error {
+	t.destroyed++
+	return nil
+}
+
+func TestRuntimeBuild(t *testing.T) {
+
+	beans.Verbose = true
+
+	ctx, err := beans.Create(
+		&runtimeElement{name: "first"},
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	h, err := ctx.Build(&buildProcessor{})
+	require.NoError(t, err)
+
+	processor := h.Object().(*buildProcessor)
+	require.NotNil(t, processor.Element)
+	require.Equal(t, 1, processor.constructed)
+	require.Equal(t, 0, processor.destroyed)
+
+	require.NoError(t, h.Close())
+	require.NoError(t, h.Close())
+	require.Equal(t, 1, processor.destroyed)
+
+	_, err = ctx.Build(&struct {
+		Missing *runtimeMissing `inject`
+	}{})
+	require.Error(t, err)
+}
+
+type runtimeNamed interface {
+	BeanName() string
+}
+
+type runtimeRequest struct {
+	Element *runtimeElement `inject`
+	Named   runtimeNamed    `inject`
+	Missing *runtimeMissing `inject:"optional"`
+}
+
+func TestRuntimeInjectCompiled(t *testing.T) {
+
+	parent, err := beans.Create()
+	require.NoError(t, err)
+	defer parent.Close()
+
+	element := &runtimeElement{name: "first"}
+	ctx, err := parent.Extend(element)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	for i := 0; i < 2; i++ {
+		request := &runtimeRequest{}
+		require.NoError(t, ctx.Inject(request))
+		require.True(t, request.Element == element)
+		require.True(t, request.Named == element)
+		require.Nil(t, request.Missing)
+	}
+
+	// register in the parent changes the child
+	missing := &runtimeMissing{}
+	require.NoError(t, parent.Register(missing))
+
+	request := &runtimeRequest{}
+	require.NoError(t, ctx.Inject(request))
+	require.True(t, request.Missing == missing)
+
+	list := ctx.Bean(reflect.TypeOf(element), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	replacement := &runtimeElement{name: "first"}
+	require.NoError(t, ctx.Replace(list[0], replacement))
+
+	request = &runtimeRequest{}
+	require.NoError(t, ctx.Inject(request))
+	require.True(t, request.Element == replacement)
+	require.True(t, request.Named == replacement)
+
+	list = parent.Bean(reflect.TypeOf(missing), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	require.NoError(t, parent.Unregister(list[0]))
+
+	request = &runtimeRequest{}
+	require.NoError(t, ctx.Inject(request))
+	require.Nil(t, request.Missing)
+}
+
+func TestRuntimeInjectAllocs(t *testing.T) {
+
+	ctx, err := beans.Create(&runtimeElement{name: "first"})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	request := &runtimeRequest{}
+	require.NoError(t, ctx.Inject(request))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := ctx.Inject(request); err != nil {
+			t.Fatal(err)
+		}
+	})
+	require.Equal(t, float64(0), allocs)
+}
+
+/**
+Runtime injection of singleton beans on the request path
+*/
+func BenchmarkRuntimeInject(b *testing.B) {
+
+	ctx, err := beans.Create(&runtimeElement{name: "first"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer ctx.Close()
+
+	b.Run("singletons", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := ctx.Inject(&runtimeRequest{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("collections", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := ctx.Inject(&struct {
+				Elements []*runtimeElement `inject`
+			}{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
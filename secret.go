@@ -0,0 +1,164 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const redacted = "******"
+
+var errMalformedSecret = errors.New("malformed secret value")
+
+/**
+Secret holds the sensitive value, like password or token, that is redacted in all string representations, JSON, text and log attributes.
+Use Value to get the plain value. Secret fields with 'value' or 'config' tags are bound from properties like strings.
+*/
+type Secret string
+
+/**
+Returns the plain value of the secret
+*/
+func (t Secret) Value() string {
+	return string(t)
+}
+
+func (t Secret) String() string {
+	return redacted
+}
+
+func (t Secret) GoString() string {
+	return "beans.Secret(" + strconv.Quote(redacted) + ")"
+}
+
+func (t Secret) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		io.WriteString(f, strconv.Quote(redacted))
+	case 'v':
+		if f.Flag('#') {
+			io.WriteString(f, t.GoString())
+			return
+		}
+		io.WriteString(f, redacted)
+	default:
+		io.WriteString(f, redacted)
+	}
+}
+
+func (t Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (t Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+func (t Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+/**
+Creates the secret property source from files in the directory, like mounted secrets of containers.
+The key of property is the relative path of the file where path separators are replaced by dots, the value is the content of the file without trailing spaces.
+Hidden files and directories are skipped.
+*/
+func SecretDir(path string) PropertySource {
+	return &dirSource{path: path}
+}
+
+type dirSource struct {
+	path string
+}
+
+func (t *dirSource) IsSecret(key string) bool {
+	return true
+}
+
+func (t *dirSource) Properties() (map[string]string, error) {
+	values := make(map[string]string)
+	return values, t.readDir(t.path, "", values)
+}
+
+func (t *dirSource) readDir(dir, prefix string, values map[string]string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// follow symbolic links
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		key := joinKey(prefix, name)
+		if info.IsDir() {
+			if err := t.readDir(path, key, values); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		values[key] = strings.TrimRight(string(content), " \t\r\n")
+	}
+	return nil
+}
+
+/**
+Creates the property source from environment variables with the prefix.
+The key of property is the name of variable without prefix in lower case where underscores are replaced by dots,
+for example APP_DB_PASSWORD with prefix 'APP_' is 'db.password'.
+*/
+func PropertyEnv(prefix string) PropertySource {
+	return &envSource{prefix: prefix}
+}
+
+/**
+Creates the secret property source from environment variables with the prefix, keys are the same as in PropertyEnv
+*/
+func SecretEnv(prefix string) PropertySource {
+	return &secretEnvSource{envSource{prefix: prefix}}
+}
+
+type envSource struct {
+	prefix string
+}
+
+func (t *envSource) Properties() (map[string]string, error) {
+	values := make(map[string]string)
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], t.prefix) || len(kv[0]) == len(t.prefix) {
+			continue
+		}
+		key := strings.ReplaceAll(strings.ToLower(kv[0][len(t.prefix):]), "_", ".")
+		values[key] = kv[1]
+	}
+	return values, nil
+}
+
+type secretEnvSource struct {
+	envSource
+}
+
+func (t *secretEnvSource) IsSecret(key string) bool {
+	return true
+}
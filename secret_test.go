@@ -0,0 +1,123 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type credentialsConfig struct {
+	_        struct{}     `config:"prefix=db"`
+	User     string       `config:"user"`
+	Password beans.Secret `config:"password,required"`
+}
+
+type credentialsUser struct {
+	Config   *credentialsConfig `inject`
+	Token    beans.Secret       `value:"api.token"`
+	Plain    string             `value:"api.token"`
+	Password string             `value:"db.password"`
+}
+
+func TestSecretFormat(t *testing.T) {
+
+	secret := beans.Secret("top")
+	require.Equal(t, "top", secret.Value())
+
+	holder := struct {
+		Password beans.Secret
+	}{secret}
+
+	for _, s := range []string{
+		secret.String(),
+		fmt.Sprint(secret),
+		fmt.Sprintf("%s %v %+v %#v %q %x", secret, secret, holder, holder, secret, secret),
+	} {
+		require.False(t, strings.Contains(s, "top"), s)
+	}
+
+	data, err := json.Marshal(holder)
+	require.NoError(t, err)
+	require.False(t, strings.Contains(string(data), "top"))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("login", "password", secret)
+	require.False(t, strings.Contains(buf.String(), "top"))
+	require.True(t, strings.Contains(buf.String(), "password"))
+}
+
+func TestSecretSources(t *testing.T) {
+
+	beans.Verbose = true
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db", "password"), []byte("s3cret\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("skip"), 0600))
+
+	t.Setenv("BEANS_TEST_SECRET_API_TOKEN", "t0ken")
+	t.Setenv("BEANS_TEST_DB_USER", "admin")
+
+	user := &credentialsUser{}
+	ctx, err := beans.Create(
+		beans.SecretDir(dir),
+		beans.PropertyEnv("BEANS_TEST_"),
+		beans.SecretEnv("BEANS_TEST_SECRET_"),
+		&credentialsConfig{},
+		user,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, "admin", user.Config.User)
+	require.Equal(t, "s3cret", user.Config.Password.Value())
+	require.Equal(t, "s3cret", user.Password)
+	require.Equal(t, "t0ken", user.Token.Value())
+	require.Equal(t, "t0ken", user.Plain)
+
+	value, ok := ctx.Property("api.token")
+	require.True(t, ok)
+	require.Equal(t, "t0ken", value)
+
+	props := ctx.Properties()
+	require.Equal(t, "******", props["db.password"])
+	require.Equal(t, "******", props["api.token"])
+	require.Equal(t, "admin", props["db.user"])
+	_, ok = props[".hidden"]
+	require.False(t, ok)
+
+	require.False(t, strings.Contains(fmt.Sprintf("%+v", user.Config), "s3cret"))
+}
+
+func TestSecretMalformed(t *testing.T) {
+
+	_, err := beans.Create(
+		beans.SecretEnv("BEANS_TEST_PORT_"),
+		&struct {
+			Port int `value:"number,default=1"`
+		}{},
+	)
+	require.NoError(t, err)
+
+	t.Setenv("BEANS_TEST_PORT_NUMBER", "hidden-value")
+	_, err = beans.Create(
+		beans.SecretEnv("BEANS_TEST_PORT_"),
+		&struct {
+			Port int `value:"number"`
+		}{},
+	)
+	require.Error(t, err)
+	require.False(t, strings.Contains(err.Error(), "hidden-value"), err.Error())
+}
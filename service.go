@@ -0,0 +1,252 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	gocontext "context"
+	"fmt"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+/**
+Supervisor runs services of the context
+*/
+type supervisor struct {
+
+	/**
+	Parent context of all services, cancelled on Close
+	*/
+	ctx    gocontext.Context
+	cancel gocontext.CancelFunc
+
+	/**
+	Running services
+	*/
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	runs map[*bean]*serviceRun
+
+	/**
+	Errors of services failed after all restarts
+	*/
+	errs []error
+
+	/**
+	Closed when context starts closing
+	*/
+	done     chan struct{}
+	doneOnce sync.Once
+
+	/**
+	Error of the service escalated to the context
+	*/
+	escalated error
+}
+
+type serviceRun struct {
+	cancel gocontext.CancelFunc
+	done   chan struct{}
+}
+
+func newSupervisor() *supervisor {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	return &supervisor{
+		ctx:    ctx,
+		cancel: cancel,
+		runs:   make(map[*bean]*serviceRun),
+		done:   make(chan struct{}),
+	}
+}
+
+func (t *context) Wait() error {
+	t.services.wg.Wait()
+	t.services.mu.Lock()
+	defer t.services.mu.Unlock()
+	return multipleErr(t.services.errs)
+}
+
+func (t *context) Done() <-chan struct{} {
+	return t.services.done
+}
+
+func (t *context) Err() error {
+	t.services.mu.Lock()
+	defer t.services.mu.Unlock()
+	return t.services.escalated
+}
+
+/**
+Launches initialized services of the context in initialization order
+*/
+func (t *context) launchServices() {
+	for _, b := range initOrder(t.coreBeans()) {
+		if _, ok := b.obj.(Service); ok && b.lifecycle == BeanInitialized {
+			t.launchService(b)
+		}
+	}
+}
+
+func (t *context) launchService(b *bean) {
+
+	s := t.services
+	s.mu.Lock()
+	if _, ok := s.runs[b]; ok || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := gocontext.WithCancel(s.ctx)
+	run := &serviceRun{cancel: cancel, done: make(chan struct{})}
+	s.runs[b] = run
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	svc := b.obj.(Service)
+	policy := servicePolicy(svc)
+
+	if Verbose {
+		fmt.Printf("Launch service '%s' with type '%v' and policy %+v\n", b.name, b.beanDef.classPtr, policy)
+	}
+
+	go func() {
+		err := superviseService(ctx, b.name, svc, policy)
+		cancel()
+
+		s.mu.Lock()
+		if s.runs[b] == run {
+			delete(s.runs, b)
+		}
+		if err != nil {
+			s.errs = append(s.errs, err)
+			t.metrics.fail(PhaseService)
+		}
+		escalate := err != nil && policy.Escalate && s.escalated == nil && s.ctx.Err() == nil
+		if escalate {
+			s.escalated = err
+		}
+		s.mu.Unlock()
+
+		close(run.done)
+		s.wg.Done()
+
+		if escalate {
+			if Verbose {
+				fmt.Printf("Service '%s' escalates failure to the context, %v\n", b.name, err)
+			}
+			t.Close()
+		}
+	}()
+}
+
+/**
+Cancels the service and waits until it exits
+*/
+func (t *context) cancelService(b *bean) {
+	t.services.mu.Lock()
+	run, ok := t.services.runs[b]
+	t.services.mu.Unlock()
+	if ok {
+		run.cancel()
+		<-run.done
+	}
+}
+
+/**
+Cancels all services of the context and waits until they exit
+*/
+func (t *context) cancelServices() {
+	s := t.services
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+	s.mu.Lock()
+	s.cancel()
+	runs := make([]*serviceRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, run)
+	}
+	s.mu.Unlock()
+	for _, run := range runs {
+		<-run.done
+	}
+}
+
+/**
+Delay before the first restart of the service if the policy has no backoff
+*/
+const DefaultBackoff = 100 * time.Millisecond
+
+func servicePolicy(svc Service) ServicePolicy {
+	if supervised, ok := svc.(SupervisedService); ok {
+		return supervised.Policy()
+	}
+	return ServicePolicy{}
+}
+
+/**
+Runs the service and restarts it by policy, returns the error if service failed after all restarts
+*/
+func superviseService(ctx gocontext.Context, name string, svc Service, policy ServicePolicy) error {
+
+	backoff := policy.Backoff
+	if backoff <= 0 {
+		// the service failing or returning immediately must not restart in a busy loop
+		backoff = DefaultBackoff
+	}
+	for restarts := 0; ; restarts++ {
+
+		err := runService(ctx, name, svc)
+		if ctx.Err() != nil {
+			// cancelled by context
+			return nil
+		}
+
+		switch {
+		case err == nil && policy.Restart != RestartAlways:
+			return nil
+		case err != nil && policy.Restart == RestartNever:
+			return err
+		}
+
+		if policy.MaxRestarts > 0 && restarts >= policy.MaxRestarts {
+			if err != nil {
+				return errors.Errorf("service '%s' failed after %d restarts, %v", name, restarts, err)
+			}
+			return nil
+		}
+
+		if Verbose {
+			fmt.Printf("Restart service '%s' in %v, %v\n", name, backoff, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+func runService(ctx gocontext.Context, name string, svc Service) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("service '%s' recovered with error %v", name, r)
+		}
+	}()
+
+	if err := svc.Run(ctx); err != nil {
+		return errors.Errorf("service '%s' failed, %v", name, err)
+	}
+	return nil
+}
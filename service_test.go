@@ -0,0 +1,176 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type tickerService struct {
+	started   chan struct{}
+	cancelled atomic.Bool
+	destroyed atomic.Bool
+}
+
+func (t *tickerService) Run(ctx context.Context) error {
+	close(t.started)
+	<-ctx.Done()
+	t.cancelled.Store(true)
+	return ctx.Err()
+}
+
+func (t *tickerService) Destroy() error {
+	// service exits before destroy
+	t.destroyed.Store(t.cancelled.Load())
+	return nil
+}
+
+type flakyService struct {
+	runs   atomic.Int32
+	policy beans.ServicePolicy
+	panics bool
+}
+
+func (t *flakyService) Policy() beans.ServicePolicy {
+	return t.policy
+}
+
+func (t *flakyService) Run(ctx context.Context) error {
+	n := t.runs.Add(1)
+	if t.panics && n == 1 {
+		panic("first run")
+	}
+	return errors.Errorf("run %d failed", n)
+}
+
+func TestServiceCancelOnClose(t *testing.T) {
+
+	beans.Verbose = true
+
+	service := &tickerService{started: make(chan struct{})}
+	ctx, err := beans.Create(service)
+	require.NoError(t, err)
+
+	select {
+	case <-service.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service is not launched")
+	}
+
+	require.NoError(t, ctx.Close())
+	require.True(t, service.cancelled.Load())
+	require.True(t, service.destroyed.Load())
+	require.NoError(t, ctx.Wait())
+	require.Nil(t, ctx.Err())
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("context is not done")
+	}
+}
+
+func TestServiceRestartAndEscalate(t *testing.T) {
+
+	service := &flakyService{
+		panics: true,
+		policy: beans.ServicePolicy{
+			Restart:     beans.RestartOnFailure,
+			MaxRestarts: 3,
+			Backoff:     time.Millisecond,
+			MaxBackoff:  2 * time.Millisecond,
+			Escalate:    true,
+		},
+	}
+	watcher := &tickerService{started: make(chan struct{})}
+	ctx, err := beans.Create(service, watcher)
+	require.NoError(t, err)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("failure is not escalated")
+	}
+
+	err = ctx.Wait()
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "after 3 restarts"), err.Error())
+	require.Equal(t, int32(4), service.runs.Load())
+	require.Error(t, ctx.Err())
+
+	// escalation closes the context and cancels other services
+	require.True(t, watcher.cancelled.Load())
+	require.NoError(t, ctx.Close())
+}
+
+func TestServiceWithoutPolicy(t *testing.T) {
+
+	service := &flakyService{}
+	ctx, err := beans.Create(service)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	err = ctx.Wait()
+	require.Error(t, err)
+	require.Equal(t, int32(1), service.runs.Load())
+	require.Nil(t, ctx.Err())
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("context is closed without escalation")
+	default:
+	}
+}
+
+func TestServiceDefaultBackoff(t *testing.T) {
+
+	service := &flakyService{
+		policy: beans.ServicePolicy{
+			Restart: beans.RestartAlways,
+		},
+	}
+	ctx, err := beans.Create(service)
+	require.NoError(t, err)
+
+	// restarts after 100ms and 300ms, instead of a busy loop without the backoff
+	time.Sleep(250 * time.Millisecond)
+	require.NoError(t, ctx.Close())
+	require.True(t, service.runs.Load() <= 3, "runs %d", service.runs.Load())
+	require.True(t, service.runs.Load() >= 2, "runs %d", service.runs.Load())
+}
+
+type Waiter interface {
+	Wait() error
+}
+
+type jobWaiter struct {
+}
+
+func (t *jobWaiter) Wait() error {
+	return nil
+}
+
+type waiterHolder struct {
+	Waiter Waiter `inject`
+}
+
+func TestContextNotCandidateOfWaiter(t *testing.T) {
+
+	// the context has methods Wait, Done and Err too, but it is not the candidate of user interfaces
+	waiter := &jobWaiter{}
+	holder := &waiterHolder{}
+	ctx, err := beans.Create(waiter, holder)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.True(t, holder.Waiter == waiter)
+}
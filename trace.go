@@ -0,0 +1,281 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+Kind of the traced operation
+*/
+type SpanKind string
+
+const (
+	SpanCreate        SpanKind = "create"
+	SpanClose         SpanKind = "close"
+	SpanConstruct     SpanKind = "construct"
+	SpanPostConstruct SpanKind = "postConstruct"
+	SpanObject        SpanKind = "object"
+	SpanDestroy       SpanKind = "destroy"
+	SpanReload        SpanKind = "reload"
+	SpanInject        SpanKind = "inject"
+)
+
+/**
+Traced operation
+*/
+type Span struct {
+	/**
+	Unique id of the span in the process
+	*/
+	ID uint64
+	/**
+	Id of the parent span, zero for root spans
+	*/
+	ParentID uint64
+	Kind     SpanKind
+	/**
+	Name of the bean, empty for context operations and runtime Inject
+	*/
+	Bean string
+	/**
+	Type of the bean or the object
+	*/
+	Type  string
+	Start time.Time
+	End   time.Time
+	Err   error
+}
+
+func (t *Span) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+var spanIDs atomic.Uint64
+
+/**
+Tracing state of the context
+*/
+type tracing struct {
+	tracer Tracer
+
+	mu sync.Mutex
+	/**
+	Span of the current context operation, parent of spans without constructing bean
+	*/
+	root *Span
+	/**
+	Construction spans of beans being constructed
+	*/
+	active map[*bean]*Span
+}
+
+type tracers []Tracer
+
+func (t tracers) StartSpan(span *Span) {
+	for _, tracer := range t {
+		tracer.StartSpan(span)
+	}
+}
+
+func (t tracers) EndSpan(span *Span) {
+	for _, tracer := range t {
+		tracer.EndSpan(span)
+	}
+}
+
+func newTracing(list []Tracer, parent *context) *tracing {
+	t := &tracing{active: make(map[*bean]*Span)}
+	switch len(list) {
+	case 0:
+		if parent != nil {
+			t.tracer = parent.tracing.tracer
+		}
+	case 1:
+		t.tracer = list[0]
+	default:
+		t.tracer = tracers(list)
+	}
+	return t
+}
+
+func noTrace(error) {
+}
+
+/**
+Creates the span, tracer is called by caller outside of the lock
+*/
+func (t *tracing) start(kind SpanKind, name string, typ reflect.Type, parent *Span) *Span {
+	span := &Span{
+		ID:    spanIDs.Add(1),
+		Kind:  kind,
+		Bean:  name,
+		Start: time.Now(),
+	}
+	if typ != nil {
+		span.Type = typ.String()
+	}
+	if parent != nil {
+		span.ParentID = parent.ID
+	}
+	return span
+}
+
+func (t *tracing) end(span *Span, err error) {
+	span.End = time.Now()
+	span.Err = err
+	t.tracer.EndSpan(span)
+}
+
+/**
+Traces the context operation, spans of beans started until the end are children of the operation
+*/
+func (t *context) traceOperation(kind SpanKind, name string, typ reflect.Type) func(error) {
+	tr := t.tracing
+	if tr.tracer == nil {
+		return noTrace
+	}
+	tr.mu.Lock()
+	prev := tr.root
+	span := tr.start(kind, name, typ, prev)
+	tr.root = span
+	tr.mu.Unlock()
+	tr.tracer.StartSpan(span)
+	return func(err error) {
+		tr.mu.Lock()
+		tr.root = prev
+		tr.mu.Unlock()
+		tr.end(span, err)
+	}
+}
+
+/**
+Traces construction of the bean as child of construction of the last bean in the stack
+*/
+func (t *context) traceConstruct(b *bean, stack []*bean) func(error) {
+	tr := t.tracing
+	if tr.tracer == nil {
+		return noTrace
+	}
+	tr.mu.Lock()
+	parent := tr.root
+	if len(stack) > 0 {
+		if s, ok := tr.active[stack[len(stack)-1]]; ok {
+			parent = s
+		}
+	}
+	span := tr.start(SpanConstruct, b.name, b.beanDef.classPtr, parent)
+	// bean in the cycle is already active
+	_, cycle := tr.active[b]
+	if !cycle {
+		tr.active[b] = span
+	}
+	tr.mu.Unlock()
+	tr.tracer.StartSpan(span)
+	return func(err error) {
+		if !cycle {
+			tr.mu.Lock()
+			delete(tr.active, b)
+			tr.mu.Unlock()
+		}
+		tr.end(span, err)
+	}
+}
+
+/**
+Traces the call on the bean as child of construction of the owner bean or the current operation
+*/
+func (t *context) traceCall(kind SpanKind, b *bean, owner *bean) func(error) {
+	tr := t.tracing
+	if tr.tracer == nil {
+		return noTrace
+	}
+	tr.mu.Lock()
+	parent := tr.root
+	if s, ok := tr.active[owner]; ok {
+		parent = s
+	}
+	span := tr.start(kind, b.name, b.beanDef.classPtr, parent)
+	tr.mu.Unlock()
+	tr.tracer.StartSpan(span)
+	return func(err error) {
+		tr.end(span, err)
+	}
+}
+
+/**
+Traces reload or replacement of the bean
+*/
+func (t *context) traceReload(instance Bean) func(error) {
+	if b, ok := instance.(*bean); ok && b != nil && b.beanDef != nil {
+		return t.traceOperation(SpanReload, b.name, b.beanDef.classPtr)
+	}
+	return t.traceOperation(SpanReload, "", nil)
+}
+
+/**
+Traces runtime injection of the object, spans are roots since injection runs concurrently with other operations
+*/
+func (t *context) traceInject(typ reflect.Type) func(error) {
+	tr := t.tracing
+	if tr.tracer == nil {
+		return noTrace
+	}
+	span := tr.start(SpanInject, "", typ, nil)
+	tr.tracer.StartSpan(span)
+	return func(err error) {
+		tr.end(span, err)
+	}
+}
+
+/**
+Creates the tracer that writes ended spans to the writer as JSON lines
+*/
+func JSONTracer(w io.Writer) Tracer {
+	return &jsonTracer{enc: json.NewEncoder(w)}
+}
+
+type jsonTracer struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+type jsonSpan struct {
+	ID       uint64    `json:"id"`
+	ParentID uint64    `json:"parentId,omitempty"`
+	Kind     SpanKind  `json:"kind"`
+	Bean     string    `json:"bean,omitempty"`
+	Type     string    `json:"type,omitempty"`
+	Start    time.Time `json:"start"`
+	Duration int64     `json:"durationNs"`
+	Error    string    `json:"error,omitempty"`
+}
+
+func (t *jsonTracer) StartSpan(span *Span) {
+}
+
+func (t *jsonTracer) EndSpan(span *Span) {
+	js := jsonSpan{
+		ID:       span.ID,
+		ParentID: span.ParentID,
+		Kind:     span.Kind,
+		Bean:     span.Bean,
+		Type:     span.Type,
+		Start:    span.Start,
+		Duration: int64(span.Duration()),
+	}
+	if span.Err != nil {
+		js.Error = span.Err.Error()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enc.Encode(js)
+}
@@ -0,0 +1,218 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recordingTracer struct {
+	mu      sync.Mutex
+	started int
+	spans   []*beans.Span
+}
+
+func (t *recordingTracer) StartSpan(span *beans.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started++
+}
+
+func (t *recordingTracer) EndSpan(span *beans.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, span)
+}
+
+func (t *recordingTracer) find(kind beans.SpanKind, name string) []*beans.Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var list []*beans.Span
+	for _, span := range t.spans {
+		if span.Kind == kind && span.Bean == name {
+			list = append(list, span)
+		}
+	}
+	return list
+}
+
+func (t *recordingTracer) byID() map[uint64]*beans.Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := make(map[uint64]*beans.Span)
+	for _, span := range t.spans {
+		m[span.ID] = span
+	}
+	return m
+}
+
+type tracedConnection struct {
+}
+
+var tracedConnectionClass = reflect.TypeOf((*tracedConnection)(nil))
+
+type tracedConnectionFactory struct {
+}
+
+func (t *tracedConnectionFactory) Object() (interface{}, error) {
+	return &tracedConnection{}, nil
+}
+
+func (t *tracedConnectionFactory) ObjectType() reflect.Type {
+	return tracedConnectionClass
+}
+
+func (t *tracedConnectionFactory) ObjectName() string {
+	return ""
+}
+
+func (t *tracedConnectionFactory) Singleton() bool {
+	return true
+}
+
+type tracedRepository struct {
+	Connection *tracedConnection `inject`
+}
+
+func (t *tracedRepository) PostConstruct() error {
+	return nil
+}
+
+func (t *tracedRepository) Destroy() error {
+	return nil
+}
+
+type tracedService struct {
+	Repository *tracedRepository `inject`
+}
+
+func (t *tracedService) PostConstruct() error {
+	return nil
+}
+
+func TestTracer(t *testing.T) {
+
+	tracer := &recordingTracer{}
+	ctx, err := beans.Create(tracer, &tracedService{}, &tracedRepository{}, &tracedConnectionFactory{})
+	require.NoError(t, err)
+
+	spans := tracer.byID()
+	create := tracer.find(beans.SpanCreate, "")
+	require.Equal(t, 1, len(create))
+	require.Equal(t, uint64(0), create[0].ParentID)
+	require.NoError(t, create[0].Err)
+
+	service := tracer.find(beans.SpanConstruct, "*beans_test.tracedService")
+	require.Equal(t, 1, len(service))
+	require.Equal(t, create[0].ID, service[0].ParentID)
+
+	// dependency is constructed either by the dependent bean or by the context, depending on scan order
+	repository := tracer.find(beans.SpanConstruct, "*beans_test.tracedRepository")
+	require.Equal(t, 1, len(repository))
+	require.Contains(t, []uint64{create[0].ID, service[0].ID}, repository[0].ParentID)
+
+	post := tracer.find(beans.SpanPostConstruct, "*beans_test.tracedRepository")
+	require.Equal(t, 1, len(post))
+	require.Equal(t, repository[0].ID, post[0].ParentID)
+	require.False(t, post[0].End.Before(post[0].Start))
+
+	object := tracer.find(beans.SpanObject, "*beans_test.tracedConnectionFactory")
+	require.Equal(t, 1, len(object))
+	parent := spans[object[0].ParentID]
+	require.Equal(t, beans.SpanConstruct, parent.Kind)
+	require.Contains(t, []string{"*beans_test.tracedRepository", "*beans_test.tracedConnection"}, parent.Bean)
+
+	require.NoError(t, ctx.Inject(&tracedService{}))
+	inject := tracer.find(beans.SpanInject, "")
+	require.Equal(t, 1, len(inject))
+	require.Equal(t, "*beans_test.tracedService", inject[0].Type)
+
+	list := ctx.Lookup("*beans_test.tracedRepository", 0)
+	require.Equal(t, 1, len(list))
+	require.NoError(t, ctx.ReloadCascade(list[0]))
+
+	reload := tracer.find(beans.SpanReload, "*beans_test.tracedRepository")
+	require.Equal(t, 1, len(reload))
+	destroy := tracer.find(beans.SpanDestroy, "*beans_test.tracedRepository")
+	require.Equal(t, 1, len(destroy))
+	require.Equal(t, reload[0].ID, destroy[0].ParentID)
+
+	require.NoError(t, ctx.Close())
+	closing := tracer.find(beans.SpanClose, "")
+	require.Equal(t, 1, len(closing))
+	destroy = tracer.find(beans.SpanDestroy, "*beans_test.tracedRepository")
+	require.Equal(t, 2, len(destroy))
+	require.Equal(t, closing[0].ID, destroy[1].ParentID)
+
+	tracer.mu.Lock()
+	require.Equal(t, tracer.started, len(tracer.spans))
+	tracer.mu.Unlock()
+}
+
+func TestTracerChild(t *testing.T) {
+
+	tracer := &recordingTracer{}
+	parent, err := beans.Create(tracer)
+	require.NoError(t, err)
+	defer parent.Close()
+
+	child, err := parent.Extend(&tracedService{}, &tracedRepository{}, &tracedConnectionFactory{})
+	require.NoError(t, err)
+	require.NoError(t, child.Close())
+
+	require.Equal(t, 2, len(tracer.find(beans.SpanCreate, "")))
+	require.Equal(t, 1, len(tracer.find(beans.SpanConstruct, "*beans_test.tracedService")))
+	require.Equal(t, 1, len(tracer.find(beans.SpanClose, "")))
+}
+
+func TestJSONTracer(t *testing.T) {
+
+	var out bytes.Buffer
+	ctx, err := beans.Create(beans.JSONTracer(&out), &tracedService{}, &tracedRepository{}, &tracedConnectionFactory{})
+	require.NoError(t, err)
+	require.NoError(t, ctx.Close())
+
+	kinds := make(map[string]int)
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var span map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		require.NotNil(t, span["id"])
+		require.NotNil(t, span["start"])
+		require.NotNil(t, span["durationNs"])
+		kinds[span["kind"].(string)]++
+	}
+	require.Equal(t, 1, kinds["create"])
+	require.Equal(t, 1, kinds["close"])
+	require.Equal(t, 2, kinds["postConstruct"])
+	require.Equal(t, 1, kinds["object"])
+	require.Equal(t, 1, kinds["destroy"])
+}
+
+func TestTracerBeanReload(t *testing.T) {
+
+	tracer := &recordingTracer{}
+	ctx, err := beans.Create(tracer, &tracedRepository{}, &tracedConnectionFactory{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(reflect.TypeOf((*tracedRepository)(nil)), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	require.NoError(t, list[0].Reload())
+
+	reload := tracer.find(beans.SpanReload, "*beans_test.tracedRepository")
+	require.Equal(t, 1, len(reload))
+	post := tracer.find(beans.SpanPostConstruct, "*beans_test.tracedRepository")
+	require.Equal(t, 2, len(post))
+	require.Equal(t, reload[0].ID, post[1].ParentID)
+	require.Equal(t, uint64(1), ctx.Metrics().Reloads)
+}
@@ -0,0 +1,174 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var typedClientClass = reflect.TypeOf((*typedClient)(nil))
+
+type typedClient struct {
+	endpoint string
+}
+
+var TypedEndpointClass = reflect.TypeOf((*TypedEndpoint)(nil)).Elem()
+
+type TypedEndpoint interface {
+	Endpoint() string
+}
+
+type typedEndpointImpl struct {
+	endpoint string
+}
+
+func (t *typedEndpointImpl) Endpoint() string {
+	return t.endpoint
+}
+
+type typedClientFactory struct {
+	Endpoint TypedEndpoint `inject`
+}
+
+func (t *typedClientFactory) Object() (*typedClient, error) {
+	return &typedClient{endpoint: t.Endpoint.Endpoint()}, nil
+}
+
+func (t *typedClientFactory) ObjectName() string {
+	return "typedClient"
+}
+
+func (t *typedClientFactory) Singleton() bool {
+	return true
+}
+
+var _ beans.Factory[*typedClient] = (*typedClientFactory)(nil)
+
+type typedClientConsumer struct {
+	Client   *typedClient  `inject:"bean=typedClient"`
+	Endpoint TypedEndpoint `inject`
+}
+
+func TestTypedFactory(t *testing.T) {
+
+	beans.Verbose = true
+
+	consumer := &typedClientConsumer{}
+	ctx, err := beans.Create(
+		&typedClientFactory{},
+		beans.FactoryFunc(func() (TypedEndpoint, error) {
+			return &typedEndpointImpl{endpoint: "localhost:8080"}, nil
+		}),
+		consumer,
+	)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.NotNil(t, consumer.Client)
+	require.Equal(t, "localhost:8080", consumer.Client.endpoint)
+	require.Equal(t, "localhost:8080", consumer.Endpoint.Endpoint())
+
+	client, ok := beans.GetBean[*typedClient](ctx, typedClientClass)
+	require.True(t, ok)
+	require.Equal(t, consumer.Client, client)
+
+	list := ctx.Bean(TypedEndpointClass, beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	_, ok = list[0].FactoryBean()
+	require.True(t, ok)
+}
+
+type mismatchFactoryBean struct {
+}
+
+func (t *mismatchFactoryBean) Object() (interface{}, error) {
+	return &typedEndpointImpl{}, nil
+}
+
+func (t *mismatchFactoryBean) ObjectType() reflect.Type {
+	return typedClientClass
+}
+
+func (t *mismatchFactoryBean) ObjectName() string {
+	return ""
+}
+
+func (t *mismatchFactoryBean) Singleton() bool {
+	return true
+}
+
+func TestFactoryObjectTypeMismatch(t *testing.T) {
+
+	beans.Verbose = true
+
+	ctx, err := beans.Create(
+		&mismatchFactoryBean{},
+	)
+	require.Error(t, err)
+	require.Nil(t, ctx)
+	require.True(t, strings.Contains(err.Error(), "not assignable"))
+	println(err.Error())
+}
+
+/**
+Has methods of Factory[T], but produces the value that can not be injected
+*/
+type typedSettings struct {
+}
+
+func (t *typedSettings) Object() (string, error) {
+	return "settings", nil
+}
+
+func (t *typedSettings) ObjectName() string {
+	return "settings"
+}
+
+func (t *typedSettings) Singleton() bool {
+	return true
+}
+
+func TestTypedFactoryValueType(t *testing.T) {
+
+	settings := &typedSettings{}
+	ctx, err := beans.Create(settings)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	list := ctx.Bean(reflect.TypeOf(settings), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	require.True(t, list[0].Object() == settings)
+	_, ok := list[0].FactoryBean()
+	require.False(t, ok)
+}
+
+type typedNilFactory struct {
+}
+
+func (t *typedNilFactory) Object() (*typedClient, error) {
+	return nil, nil
+}
+
+func (t *typedNilFactory) ObjectName() string {
+	return ""
+}
+
+func (t *typedNilFactory) Singleton() bool {
+	return true
+}
+
+func TestTypedFactoryNilObject(t *testing.T) {
+
+	ctx, err := beans.Create(
+		&typedNilFactory{},
+	)
+	require.Error(t, err)
+	require.Nil(t, ctx)
+	require.True(t, strings.Contains(err.Error(), "produced nil object"), err.Error())
+}
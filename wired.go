@@ -0,0 +1,131 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+/**
+Creates the context from objects that are already wired with each other, usually by the code generated by beansgen.
+Injection fields are not searched, instead every object in the field must be one of the given objects, so the dependency graph is taken as is.
+Required fields of type beans.Context are set to the created context.
+Properties, PostConstruct, lifecycle, services and events are handled the same way as in Create.
+*/
+func Wired(objs ...interface{}) (Context, error) {
+	return createContext(nil, objs, createOptions{wired: true})
+}
+
+/**
+Registers dependencies of wired objects by values of their injection fields
+*/
+func (t *context) wireDefinitions(defs *definitions) error {
+
+	byObj := make(map[wiredKey]*bean)
+	for classPtr, list := range t.core {
+		if classPtr.Kind() != reflect.Ptr {
+			// functions are not comparable and have no lifecycle to depend on
+			continue
+		}
+		for _, b := range list {
+			if b.obj != nil {
+				byObj[wiredKey{classPtr: classPtr, pointer: b.valuePtr.Pointer()}] = b
+			}
+		}
+	}
+
+	for _, injects := range defs.pointers {
+		for _, inject := range injects {
+			if err := inject.wire(t, byObj); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, injects := range defs.interfaces {
+		for _, inject := range injects {
+			if err := inject.wire(t, byObj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+/**
+Identity of the pointer bean, objects are not used as keys since they could be not comparable
+*/
+type wiredKey struct {
+	classPtr reflect.Type
+	pointer  uintptr
+}
+
+/**
+Checks that the field holds beans of the context, registers them as dependencies and caches in registry like injection does
+*/
+func (t *injection) wire(ctx *context, byObj map[wiredKey]*bean) error {
+
+	def := t.injectionDef
+	field := t.value.Field(def.fieldNum)
+
+	if !def.slice && !def.table && field.IsNil() && def.fieldType == ContextClass {
+		if !field.CanSet() {
+			return errors.Errorf("field '%s' in class '%v' is not public", def.fieldName, def.class)
+		}
+		field.Set(reflect.ValueOf(ctx))
+		return nil
+	}
+
+	var values []reflect.Value
+	switch {
+	case def.slice:
+		for i := 0; i < field.Len(); i++ {
+			values = append(values, field.Index(i))
+		}
+	case def.table:
+		iter := field.MapRange()
+		for iter.Next() {
+			values = append(values, iter.Value())
+		}
+	case !field.IsNil():
+		values = append(values, field)
+	}
+
+	if len(values) == 0 {
+		if !def.optional {
+			return errors.Errorf("required field '%s' in class '%v' is not wired", def.fieldName, def.class)
+		}
+		return nil
+	}
+
+	if def.lazy {
+		return nil
+	}
+
+	for _, value := range values {
+		if value.IsNil() {
+			return errors.Errorf("field '%s' in class '%v' holds nil bean", def.fieldName, def.class)
+		}
+		if value.Kind() == reflect.Interface {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Ptr {
+			// function fields are taken as is
+			continue
+		}
+		dep, ok := byObj[wiredKey{classPtr: value.Type(), pointer: value.Pointer()}]
+		if !ok {
+			return errors.Errorf("field '%s' in class '%v' holds object '%v' that is not a bean of the context", def.fieldName, def.class, value.Type())
+		}
+		if dep != t.bean {
+			t.bean.dependencies = append(t.bean.dependencies, dep)
+		}
+		ctx.registry.addNewBean(def.fieldType, dep)
+	}
+
+	return nil
+}
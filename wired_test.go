@@ -0,0 +1,100 @@
+/**
+  Copyright (c) 2022 Arpabet, LLC. All rights reserved.
+*/
+
+package beans_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.arpabet.com/beans"
+	"reflect"
+	"testing"
+)
+
+var wiredOrder []string
+
+type wiredStorage struct {
+	Ctx beans.Context `inject`
+}
+
+func (t *wiredStorage) PostConstruct() error {
+	wiredOrder = append(wiredOrder, "storage")
+	return nil
+}
+
+type wiredService struct {
+	Storage *wiredStorage   `inject`
+	Others  []*wiredStorage `inject:"optional"`
+	Port    int             `value:"wired.port,default=8080"`
+}
+
+func (t *wiredService) PostConstruct() error {
+	wiredOrder = append(wiredOrder, "service")
+	return nil
+}
+
+func TestWired(t *testing.T) {
+
+	wiredOrder = nil
+
+	storage := &wiredStorage{}
+	service := &wiredService{Storage: storage}
+
+	ctx, err := beans.Wired(service, storage)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, []string{"storage", "service"}, wiredOrder)
+	require.True(t, storage.Ctx == ctx)
+	require.Equal(t, 8080, service.Port)
+
+	list := ctx.Bean(reflect.TypeOf(service), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	deps := list[0].Dependencies()
+	require.Equal(t, 1, len(deps))
+	require.True(t, deps[0].Object() == storage)
+
+	list = ctx.Lookup("*beans_test.wiredStorage", beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+
+	err = ctx.ReloadCascade(deps[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"storage", "service", "storage", "service"}, wiredOrder)
+}
+
+func TestWiredErrors(t *testing.T) {
+
+	_, err := beans.Wired(&wiredService{}, &wiredStorage{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "required field 'Storage'")
+
+	_, err = beans.Wired(&wiredService{Storage: &wiredStorage{}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not a bean of the context")
+}
+
+type wiredFormatter struct {
+	Format  func() string `inject`
+	Storage *wiredStorage `inject`
+}
+
+func TestWiredFunction(t *testing.T) {
+
+	format := func() string {
+		return "wired"
+	}
+	storage := &wiredStorage{}
+	formatter := &wiredFormatter{Format: format, Storage: storage}
+
+	ctx, err := beans.Wired(formatter, storage, format)
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	require.Equal(t, "wired", formatter.Format())
+
+	list := ctx.Bean(reflect.TypeOf(formatter), beans.DefaultLevel)
+	require.Equal(t, 1, len(list))
+	deps := list[0].Dependencies()
+	require.Equal(t, 1, len(deps))
+	require.True(t, deps[0].Object() == storage)
+}